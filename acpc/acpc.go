@@ -0,0 +1,254 @@
+// Package acpc speaks the text protocol of the Annual Computer Poker Competition dealer (version 2.0.0)
+//
+// Match states look like MATCHSTATE:<position>:<hand>:<betting>:<cards>, e.g. MATCHSTATE:1:7:cr/c:|Ks/Th
+// where betting rounds and public cards of consecutive rounds are separated with '/'. Raises of no-limit
+// dealers carry their size (total chips committed by the raising player in the hand, e.g. r150), such raises
+// are mapped to bets of the game tree by Client.Translator (sizes are ignored without it). Position 0 is
+// acting.PlayerA (first to act in every round). Only games implementing games.PokerGameState are
+// supported (kuhn and rhodeisland), there is no limit hold'em in the repository and its match states (two
+// hole cards, three card flop) are rejected by ParseMatchState as unsupported game.
+package acpc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/notation"
+	"github.com/int8/go-counterfactual-regret-minimization/rounds"
+	"github.com/int8/go-counterfactual-regret-minimization/translation"
+)
+
+const Version = "VERSION:2.0.0"
+
+// MatchState - state of a hand as seen by player at Position
+type MatchState struct {
+	Position int
+	Hand     int
+	// Betting - actions (f, c, r optionally followed by raise size) of every betting round so far
+	Betting []string
+	// HoleCards - private cards of positions 0 and 1, nil when hidden
+	HoleCards [2]*cards.Card
+	Board     []cards.Card
+}
+
+// ParseMatchState - parses MATCHSTATE line (without trailing action or line ending)
+func ParseMatchState(line string) (MatchState, error) {
+	fields := strings.Split(line, ":")
+	if len(fields) != 5 || fields[0] != "MATCHSTATE" {
+		return MatchState{}, fmt.Errorf("acpc: malformed match state %q", line)
+	}
+	ms := MatchState{}
+	var err error
+	if ms.Position, err = strconv.Atoi(fields[1]); err != nil || ms.Position < 0 || ms.Position > 1 {
+		return MatchState{}, fmt.Errorf("acpc: invalid position in %q", line)
+	}
+	if ms.Hand, err = strconv.Atoi(fields[2]); err != nil {
+		return MatchState{}, fmt.Errorf("acpc: invalid hand number in %q", line)
+	}
+	ms.Betting = strings.Split(fields[3], "/")
+	for _, round := range ms.Betting {
+		if _, err := parseBetting(round); err != nil {
+			return MatchState{}, fmt.Errorf("acpc: invalid betting in %q", line)
+		}
+	}
+
+	cardRounds := strings.Split(fields[4], "/")
+	holeCards := strings.Split(cardRounds[0], "|")
+	if len(holeCards) != 2 {
+		return MatchState{}, fmt.Errorf("acpc: invalid hole cards in %q", line)
+	}
+	for i, text := range holeCards {
+		if text == "" {
+			continue
+		}
+		hole, err := cards.ParseCards(text)
+		if err != nil {
+			return MatchState{}, err
+		}
+		if len(hole) > 1 {
+			return MatchState{}, unsupportedGame(line, len(hole), "hole cards")
+		}
+		if len(hole) == 0 {
+			return MatchState{}, fmt.Errorf("acpc: invalid hole cards in %q", line)
+		}
+		ms.HoleCards[i] = &hole[0]
+	}
+	for _, text := range cardRounds[1:] {
+		board, err := cards.ParseCards(text)
+		if err != nil {
+			return MatchState{}, fmt.Errorf("acpc: invalid board in %q: %v", line, err)
+		}
+		if len(board) > 1 {
+			return MatchState{}, unsupportedGame(line, len(board), "public cards in a round")
+		}
+		ms.Board = append(ms.Board, board...)
+	}
+	return ms, nil
+}
+
+// unsupportedGame - error for match states of games dealing more cards at once than kuhn and rhodeisland (e.g. hold'em)
+func unsupportedGame(line string, dealt int, what string) error {
+	return fmt.Errorf("acpc: unsupported game in %q, %v %v dealt but only one card games (kuhn, rhodeisland) are supported, hold'em is not", line, dealt, what)
+}
+
+func (ms MatchState) String() string {
+	holeCards := [2]string{}
+	for i, card := range ms.HoleCards {
+		if card != nil {
+			holeCards[i] = cards.FormatCard(*card)
+		}
+	}
+	board := ""
+	for _, card := range ms.Board {
+		// bundled games deal a single public card per round
+		board += "/" + cards.FormatCard(card)
+	}
+	return fmt.Sprintf("MATCHSTATE:%v:%v:%v:%v|%v%v", ms.Position, ms.Hand, strings.Join(ms.Betting, "/"), holeCards[0], holeCards[1], board)
+}
+
+// Seat - actor playing at position
+func Seat(position int) acting.ActorID {
+	if position == 0 {
+		return acting.PlayerA
+	}
+	return acting.PlayerB
+}
+
+// bettingAction - single action of match state betting, size is set for sized raises only
+type bettingAction struct {
+	char byte
+	size int
+}
+
+// parseBetting - actions of a betting round, e.g. "cr150c"
+func parseBetting(round string) ([]bettingAction, error) {
+	actions := []bettingAction{}
+	for i := 0; i < len(round); i++ {
+		if strings.IndexByte("fcr", round[i]) < 0 {
+			return nil, fmt.Errorf("acpc: invalid action %q", round[i])
+		}
+		action := bettingAction{char: round[i]}
+		end := i + 1
+		for end < len(round) && round[end] >= '0' && round[end] <= '9' {
+			end++
+		}
+		if end > i+1 {
+			if round[i] != 'r' {
+				return nil, fmt.Errorf("acpc: only raises have size, got %q", round[i:end])
+			}
+			action.size, _ = strconv.Atoi(round[i+1 : end])
+			i = end - 1
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// translator - translates sized raise (total chips committed by the raising player) of state, index numbers actions of the hand
+type translator func(state games.PokerGameState, raiseTo int, committed float32, index int) (acting.Action, error)
+
+// State - game state of the match state, hidden opponent card is any card not seen by the player, raise sizes are ignored
+func State(root games.GameState, ms MatchState) (games.PokerGameState, error) {
+	return replay(root, ms, nil)
+}
+
+// TranslatedState - game state of the match state with sized raises translated to bets of the game tree, draw gives
+// random number (uniform in [0, 1)) used by randomized rounding of index-th action of the hand
+func TranslatedState(root games.GameState, ms MatchState, t translation.Translator, draw func(index int) float64) (games.PokerGameState, error) {
+	return replay(root, ms, func(state games.PokerGameState, raiseTo int, committed float32, index int) (acting.Action, error) {
+		return t.Translate(state, float32(raiseTo)-committed, draw(index))
+	})
+}
+
+func replay(root games.GameState, ms MatchState, translate translator) (games.PokerGameState, error) {
+	state, ok := root.(games.PokerGameState)
+	if !ok {
+		return nil, errors.New("acpc: game is not poker")
+	}
+	betting := []bettingAction{}
+	for _, round := range ms.Betting {
+		actions, err := parseBetting(round)
+		if err != nil {
+			return nil, err
+		}
+		betting = append(betting, actions...)
+	}
+	// committed - chips put into the pot by positions so far
+	committed := [2]float32{}
+	played := 0
+	dealt := 0
+	for !state.IsTerminal() {
+		switch {
+		case state.CurrentActor().GetID() != acting.ChanceId:
+			if played == len(betting) {
+				return state, nil
+			}
+			next := betting[played]
+			var action acting.Action
+			var err error
+			if next.size > 0 && translate != nil {
+				action, err = translate(state, next.size, committed[positionOf(state.CurrentActor().GetID())], played)
+			} else {
+				action, err = findAction(state, next.char)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if sizer, ok := state.(games.BetSizer); ok {
+				committed[positionOf(state.CurrentActor().GetID())] += sizer.BetAmount(action)
+			}
+			state = state.Act(action).(games.PokerGameState)
+			played++
+		case state.Round() == rounds.Start:
+			child := games.DealPrivateCards(state, func(cardA cards.Card, cardB cards.Card) bool {
+				return matchesHoleCard(cardA, acting.PlayerA, ms) && matchesHoleCard(cardB, acting.PlayerB, ms)
+			})
+			if child == nil {
+				return nil, errors.New("acpc: hole cards can not be dealt")
+			}
+			state = child
+			// antes
+			committed = [2]float32{state.Table().Pot / 2, state.Table().Pot / 2}
+		case dealt < len(ms.Board):
+			child := games.DealPublicCard(state, ms.Board[dealt])
+			if child == nil {
+				return nil, fmt.Errorf("acpc: board card %v can not be dealt", cards.FormatCard(ms.Board[dealt]))
+			}
+			state = child
+			dealt++
+		default:
+			return nil, fmt.Errorf("acpc: board card %v is missing", dealt+1)
+		}
+	}
+	if played != len(betting) {
+		return nil, errors.New("acpc: betting continues after the hand is over")
+	}
+	return state, nil
+}
+
+func findAction(state games.GameState, char byte) (acting.Action, error) {
+	if action := notation.FindAction(state, char); action != nil {
+		return action, nil
+	}
+	return nil, fmt.Errorf("acpc: action %q is not legal", char)
+}
+
+// matchesHoleCard - private card of the seat is the one shown, hidden cards can not be any of public cards
+func matchesHoleCard(card cards.Card, seat acting.ActorID, ms MatchState) bool {
+	if shown := ms.HoleCards[positionOf(seat)]; shown != nil {
+		return card == *shown
+	}
+	return !cards.Contains(ms.Board, card)
+}
+
+func positionOf(seat acting.ActorID) int {
+	if seat == acting.PlayerA {
+		return 0
+	}
+	return 1
+}
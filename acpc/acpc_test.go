@@ -0,0 +1,169 @@
+package acpc
+
+import (
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games/bundled"
+	"github.com/int8/go-counterfactual-regret-minimization/policy"
+	"github.com/int8/go-counterfactual-regret-minimization/translation"
+)
+
+func TestMatchStateRoundTrip(t *testing.T) {
+	lines := []string{"MATCHSTATE:0:0::Ks|", "MATCHSTATE:1:7:cr/c:|Th/As", "MATCHSTATE:0:12:rc/cc/rc:Jd|Qc/Ts/Ah", "MATCHSTATE:1:3:rf:|Kh"}
+	for _, line := range lines {
+		ms, err := ParseMatchState(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ms.String() != line {
+			t.Errorf("Match state %v should be formatted back unchanged, got %v", line, ms.String())
+		}
+	}
+	ms, _ := ParseMatchState("MATCHSTATE:1:7:cr/c:|Th/As")
+	if ms.Position != 1 || ms.Hand != 7 || ms.HoleCards[0] != nil || *ms.HoleCards[1] != cards.C10Hearts || ms.Board[0] != cards.AceSpades {
+		t.Errorf("Match state fields are wrong: %+v", ms)
+	}
+
+	invalid := []string{"MATCHSTATE:2:0::Ks|", "MATCHSTATE:0:0:x:Ks|", "MATCHSTATE:0:0::Xs|", "MATCHSTATE:0:0::Ks", "MATCHSTATE:0:0::Ks|/A", "STATE:0:0::Ks|"}
+	for _, line := range invalid {
+		if _, err := ParseMatchState(line); err == nil {
+			t.Errorf("Match state %v should be rejected", line)
+		}
+	}
+}
+
+func TestHoldemMatchStatesAreUnsupported(t *testing.T) {
+	for _, line := range []string{"MATCHSTATE:0:30::9s8h|", "MATCHSTATE:1:31:r300c/:|JdTc/6dJc9c"} {
+		if _, err := ParseMatchState(line); err == nil || !strings.Contains(err.Error(), "unsupported game") {
+			t.Errorf("Hold'em match state %v should be rejected as unsupported game, got %v", line, err)
+		}
+	}
+}
+
+func TestStateReplaysMatchState(t *testing.T) {
+	root, _ := bundled.Config{Game: bundled.RhodeIsland, MinCard: 10, MaxRaises: 1}.Root()
+	ms, _ := ParseMatchState("MATCHSTATE:1:0:rc/r:|Ks/Ts")
+	state, err := State(root, ms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.CurrentActor().GetID() != acting.PlayerB || *state.PrivateCard(acting.PlayerB) != cards.KingSpades {
+		t.Errorf("Player B holding K♠ should act, got %v", state.CurrentActor().GetID())
+	}
+	if *state.PrivateCard(acting.PlayerA) == cards.C10Spades {
+		t.Errorf("Hidden card can not be one of public cards")
+	}
+	if state.Table().Pot != 50 {
+		t.Errorf("Pot should be 50 after bet-call and a flop bet, got %v", state.Table().Pot)
+	}
+
+	for _, line := range []string{"MATCHSTATE:1:0:rc/r:|Ks", "MATCHSTATE:1:0:rrr:|Ks", "MATCHSTATE:1:0:rf/c:|Ks/Ts"} {
+		ms, _ := ParseMatchState(line)
+		if _, err := State(root, ms); err == nil {
+			t.Errorf("Match state %v is not consistent with the game", line)
+		}
+	}
+}
+
+func TestSizedRaisesAreTranslated(t *testing.T) {
+	root, _ := bundled.Config{Game: bundled.RhodeIsland, MinCard: 10, MaxRaises: 1}.Root()
+	line := "MATCHSTATE:1:4:r25:|Ks"
+	ms, err := ParseMatchState(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ms.String() != line {
+		t.Errorf("Raise sizes should be kept, got %v", ms.String())
+	}
+	draws := 0
+	state, err := TranslatedState(root, ms, translation.Translator{Rounding: translation.Randomized}, func(index int) float64 {
+		draws++
+		return 0.5
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if draws != 1 || state.Table().Pot != 20 || state.CurrentActor().GetID() != acting.PlayerB {
+		t.Errorf("Raise to 25 chips should be translated to the only bet of the tree, %v draws, pot %v", draws, state.Table().Pot)
+	}
+
+	client := NewClient(root, cfr.NewStrategyMap(), 1)
+	client.Translator = &translation.Translator{Rounding: translation.Deterministic}
+	client.Policy = policy.AlwaysCall
+	if reply, err := client.Reply("MATCHSTATE:1:4:r25c/r100:|Ks/Ts"); err != nil || reply != "MATCHSTATE:1:4:r25c/r100:|Ks/Ts:c" {
+		t.Errorf("Client should answer translated raises, got %q (%v)", reply, err)
+	}
+
+	for _, line := range []string{"MATCHSTATE:1:4:c25:|Ks", "MATCHSTATE:1:4:r2x:|Ks"} {
+		if _, err := ParseMatchState(line); err == nil {
+			t.Errorf("Match state %v should be rejected", line)
+		}
+	}
+}
+
+func TestLoopbackMatch(t *testing.T) {
+	root, _ := bundled.Config{Game: bundled.Kuhn}.Root()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	errs := make(chan error, 2)
+	for _, bot := range []policy.Policy{policy.AlwaysRaise, policy.CheckFold} {
+		client := &Client{Root: root, Policy: bot, Rng: rand.New(rand.NewSource(1))}
+		go func() { errs <- client.Dial(listener.Addr().String()) }()
+	}
+	dealer := &Dealer{Root: root, Hands: 100, Rng: rand.New(rand.NewSource(1))}
+	winnings, err := dealer.Serve(listener)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+	// connection order is not known, aggressive bot wins the ante every hand
+	if winnings[0]+winnings[1] != 0 || (winnings[0] != 100 && winnings[1] != 100) {
+		t.Errorf("Aggressive bot should win 100 chips, got %v", winnings)
+	}
+}
+
+func TestStrategyClientsPlayRhodeIsland(t *testing.T) {
+	root, _ := bundled.Config{Game: bundled.RhodeIsland, MinCard: 10, MaxRaises: 1}.Root()
+	conns := [2]io.ReadWriter{}
+	errs := make(chan error, 2)
+	for i := range conns {
+		dealerEnd, clientEnd := net.Pipe()
+		conns[i] = dealerEnd
+		client := NewClient(root, cfr.NewStrategyMap(), int64(i))
+		go func() {
+			errs <- client.Play(clientEnd)
+			clientEnd.Close()
+		}()
+	}
+	dealer := &Dealer{Root: root, Hands: 50, Rng: rand.New(rand.NewSource(1))}
+	winnings, err := dealer.Run(conns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, conn := range conns {
+		conn.(net.Conn).Close()
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+	if winnings[0] != -winnings[1] {
+		t.Errorf("Match should be zero-sum, got %v", winnings)
+	}
+}
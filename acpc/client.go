@@ -0,0 +1,106 @@
+package acpc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/notation"
+	"github.com/int8/go-counterfactual-regret-minimization/policy"
+	"github.com/int8/go-counterfactual-regret-minimization/translation"
+)
+
+// Client - plays hands sent by a dealer according to a policy
+type Client struct {
+	Root   games.GameState
+	Policy policy.Policy
+	Rng    *rand.Rand
+	// Translator - maps sized raises of no-limit dealers to bets of the game tree, sizes are ignored when nil
+	Translator *translation.Translator
+	// draws - random numbers of translated actions of the current hand, the same action is translated the same way every time hand is replayed
+	hand  int
+	draws map[int]float64
+}
+
+// NewClient - client acting according to strategy, unseen information sets are played uniformly
+func NewClient(root games.GameState, strategy cfr.StrategyMap, seed int64) *Client {
+	return &Client{Root: root, Policy: policy.NewStrategyPolicy(strategy, nil), Rng: rand.New(rand.NewSource(seed))}
+}
+
+// Dial - connects to dealer at address and plays until the dealer closes connection
+func (client *Client) Dial(address string) error {
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return client.Play(conn)
+}
+
+// Play - plays over established connection until it is closed
+func (client *Client) Play(conn io.ReadWriter) error {
+	if _, err := fmt.Fprintf(conn, "%v\r\n", Version); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		reply, err := client.Reply(line)
+		if err != nil {
+			return err
+		}
+		if reply == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(conn, "%v\r\n", reply); err != nil {
+			return err
+		}
+	}
+}
+
+// Reply - reply to match state line, empty when client is not to act
+func (client *Client) Reply(line string) (string, error) {
+	ms, err := ParseMatchState(line)
+	if err != nil {
+		return "", err
+	}
+	state, err := client.state(ms)
+	if err != nil {
+		return "", err
+	}
+	if state.IsTerminal() || state.CurrentActor().GetID() != Seat(ms.Position) {
+		return "", nil
+	}
+	action := client.Policy.Sample(state, client.Rng)
+	return fmt.Sprintf("%v:%c", line, notation.ActionChar(action.Name())), nil
+}
+
+func (client *Client) state(ms MatchState) (games.PokerGameState, error) {
+	if client.Translator == nil {
+		return State(client.Root, ms)
+	}
+	if client.draws == nil || client.hand != ms.Hand {
+		client.hand, client.draws = ms.Hand, map[int]float64{}
+	}
+	return TranslatedState(client.Root, ms, *client.Translator, func(index int) float64 {
+		if _, ok := client.draws[index]; !ok {
+			client.draws[index] = client.Rng.Float64()
+		}
+		return client.draws[index]
+	})
+}
@@ -0,0 +1,147 @@
+package acpc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/notation"
+)
+
+// Dealer - minimal dealer of heads-up matches, players change positions every hand
+type Dealer struct {
+	Root  games.GameState
+	Hands int
+	Rng   *rand.Rand
+}
+
+type seat struct {
+	reader *bufio.Reader
+	writer io.Writer
+}
+
+// Serve - accepts two players on listener, deals the match and closes their connections
+func (dealer *Dealer) Serve(listener net.Listener) ([2]float64, error) {
+	conns := [2]io.ReadWriter{}
+	for i := range conns {
+		conn, err := listener.Accept()
+		if err != nil {
+			return [2]float64{}, err
+		}
+		defer conn.Close()
+		conns[i] = conn
+	}
+	return dealer.Run(conns)
+}
+
+// Run - deals the match to two connected players, returns total winnings of both
+func (dealer *Dealer) Run(conns [2]io.ReadWriter) ([2]float64, error) {
+	seats := [2]seat{}
+	for i, conn := range conns {
+		seats[i] = seat{bufio.NewReader(conn), conn}
+		line, err := seats[i].readLine()
+		if err != nil {
+			return [2]float64{}, err
+		}
+		if line != Version {
+			return [2]float64{}, fmt.Errorf("acpc: player %v speaks unsupported protocol %q", i, line)
+		}
+	}
+	winnings := [2]float64{}
+	for hand := 0; hand < dealer.Hands; hand++ {
+		// player of connection i sits at position (i + hand) % 2
+		ordered := [2]seat{seats[hand%2], seats[(hand+1)%2]}
+		value, err := dealer.dealHand(hand, ordered)
+		if err != nil {
+			return winnings, err
+		}
+		winnings[hand%2] += value
+		winnings[(hand+1)%2] -= value
+	}
+	return winnings, nil
+}
+
+// dealHand - plays single hand, returns winnings of position 0
+func (dealer *Dealer) dealHand(hand int, positions [2]seat) (float64, error) {
+	root, ok := dealer.Root.(games.PokerGameState)
+	if !ok {
+		return 0, errors.New("acpc: game is not poker")
+	}
+	state := root
+	history := []acting.Action{}
+	for !state.IsTerminal() {
+		var action acting.Action
+		if state.CurrentActor().GetID() == acting.ChanceId {
+			action = games.SampleChance(state, dealer.Rng)
+		} else {
+			lines := [2]string{}
+			for position := range positions {
+				lines[position] = matchState(state, history, position, hand).String()
+				if _, err := fmt.Fprintf(positions[position].writer, "%v\r\n", lines[position]); err != nil {
+					return 0, err
+				}
+			}
+			position := positionOf(state.CurrentActor().GetID())
+			var err error
+			if action, err = positions[position].readAction(state, lines[position]); err != nil {
+				return 0, err
+			}
+		}
+		state = state.Act(action).(games.PokerGameState)
+		history = append(history, action)
+	}
+	for position := range positions {
+		if _, err := fmt.Fprintf(positions[position].writer, "%v\r\n", matchState(state, history, position, hand)); err != nil {
+			return 0, err
+		}
+	}
+	return float64(state.Evaluate()), nil
+}
+
+// matchState - hand as seen by position, both hole cards are shown at showdown
+func matchState(state games.PokerGameState, history []acting.Action, position int, hand int) MatchState {
+	ms := MatchState{Position: position, Hand: hand, Betting: []string{""}, Board: state.Table().Cards}
+	for _, action := range history {
+		switch action.Name() {
+		case acting.DealPublicCards:
+			ms.Betting = append(ms.Betting, "")
+		case acting.DealPrivateCards:
+		default:
+			ms.Betting[len(ms.Betting)-1] += string(notation.ActionChar(action.Name()))
+		}
+	}
+	showdown := state.IsTerminal() && history[len(history)-1].Name() != acting.Fold
+	for i := range ms.HoleCards {
+		if i == position || showdown {
+			card := *state.PrivateCard(Seat(i))
+			ms.HoleCards[i] = &card
+		}
+	}
+	return ms
+}
+
+func (s seat) readLine() (string, error) {
+	line, err := s.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readAction - reads reply to match state line sent to the player
+func (s seat) readAction(state games.GameState, sent string) (acting.Action, error) {
+	line, err := s.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, sent+":") || len(line) != len(sent)+2 {
+		return nil, fmt.Errorf("acpc: unexpected reply %q to %q", line, sent)
+	}
+	return findAction(state, line[len(line)-1])
+}
@@ -1,20 +1,31 @@
 package acting
 
-type ActionName [3]bool
+import "strconv"
+
+const (
+	actionNameBits = 16
+	importedBit    = 3
+)
+
+// MaxImportedActions - number of actions an information set of imported game can have
+const MaxImportedActions = 1 << (actionNameBits - importedBit - 1)
+
+// ActionName - first 3 bits code poker actions, actions of imported games set importedBit and keep their index in the rest
+type ActionName [actionNameBits]bool
 
 type Action interface {
 	Name() ActionName
 }
 
 var (
-	NoAction         ActionName = to3BinArray(0)
-	DealPublicCards  ActionName = to3BinArray(1)
-	DealPrivateCards ActionName = to3BinArray(2)
-	Fold             ActionName = to3BinArray(3)
-	Check            ActionName = to3BinArray(4)
-	Bet              ActionName = to3BinArray(5)
-	Call             ActionName = to3BinArray(6)
-	Raise            ActionName = to3BinArray(7)
+	NoAction         ActionName = PokerActionName(to3BinArray(0))
+	DealPublicCards  ActionName = PokerActionName(to3BinArray(1))
+	DealPrivateCards ActionName = PokerActionName(to3BinArray(2))
+	Fold             ActionName = PokerActionName(to3BinArray(3))
+	Check            ActionName = PokerActionName(to3BinArray(4))
+	Bet              ActionName = PokerActionName(to3BinArray(5))
+	Call             ActionName = PokerActionName(to3BinArray(6))
+	Raise            ActionName = PokerActionName(to3BinArray(7))
 )
 
 func (m ActionName) String() string {
@@ -34,5 +45,53 @@ func (m ActionName) String() string {
 	case DealPublicCards:
 		return "DPub"
 	}
+	if index, ok := m.ImportedIndex(); ok {
+		return "#" + strconv.Itoa(index)
+	}
 	return "?"
 }
+
+// PokerActionName - action name from 3 bits packed into information sets of poker games
+func PokerActionName(bits [3]bool) ActionName {
+	name := ActionName{}
+	copy(name[:], bits[:])
+	return name
+}
+
+// ImportedActionName - name of index-th action of imported game information set, never equal to any poker action name
+func ImportedActionName(index int) ActionName {
+	if index < 0 || index >= MaxImportedActions {
+		panic("imported action index out of range")
+	}
+	name := ActionName{}
+	name[importedBit] = true
+	for i := importedBit + 1; i < actionNameBits; i++ {
+		name[i] = index&(1<<uint(i-importedBit-1)) > 0
+	}
+	return name
+}
+
+// ImportedIndex - index of action of imported game, false for poker actions
+func (m ActionName) ImportedIndex() (int, bool) {
+	if !m[importedBit] {
+		return 0, false
+	}
+	index := 0
+	for i := importedBit + 1; i < actionNameBits; i++ {
+		if m[i] {
+			index |= 1 << uint(i-importedBit-1)
+		}
+	}
+	return index, true
+}
+
+// actionWords - betting actions in plain words
+var actionWords = map[ActionName]string{Check: "check", Bet: "bet", Call: "call", Raise: "raise", Fold: "fold"}
+
+// Word - check, bet, call, raise or fold, short name of String for other actions
+func (m ActionName) Word() string {
+	if word, ok := actionWords[m]; ok {
+		return word
+	}
+	return m.String()
+}
@@ -11,3 +11,11 @@ type ActorID int8
 type Actor interface {
 	GetID() ActorID
 }
+
+// PlayerName - A or B
+func PlayerName(player ActorID) string {
+	if player == PlayerA {
+		return "A"
+	}
+	return "B"
+}
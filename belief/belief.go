@@ -0,0 +1,224 @@
+// Package belief - public belief states: probability distributions of private cards of both players (their ranges)
+// given the public history of a hand and the strategy both players follow
+//
+// Public history is written like the public part of games/notation hands: betting (f fold, c check or call, r bet or
+// raise, '/' closes a betting round) followed by public cards, e.g. "rc/r Kh". Empty history (or "-") stands for the
+// state right after private cards are dealt.
+package belief
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/notation"
+	"github.com/int8/go-counterfactual-regret-minimization/rounds"
+)
+
+// PublicState - what both players know about the hand
+type PublicState struct {
+	Betting string
+	Board   []cards.Card
+}
+
+// Deal - public state reached with one deal of private cards
+type Deal struct {
+	State games.PokerGameState
+	// Chance - probability of the private cards
+	Chance float64
+	// Reach - probability of actions of both players leading to the public state
+	Reach map[acting.ActorID]float64
+}
+
+// Belief - ranges of both players in a public state
+type Belief struct {
+	Public PublicState
+	// Probability - probability of reaching the public state
+	Probability float64
+	// Ranges - probabilities of private cards of both players, cards never held in the public state are left out
+	Ranges map[acting.ActorID]map[cards.Card]float64
+}
+
+// ParsePublicState - public state in notation, e.g. "rc/r Kh"
+func ParsePublicState(text string) (PublicState, error) {
+	public := PublicState{}
+	text = strings.TrimSpace(text)
+	if text == notation.Root {
+		return public, nil
+	}
+	for i, field := range strings.Fields(text) {
+		if i == 0 && strings.Trim(field, "fcr/") == "" {
+			public.Betting = field
+			continue
+		}
+		card, err := cards.ParseCard(field)
+		if err != nil {
+			return PublicState{}, fmt.Errorf("belief: invalid public state %q: %v", text, err)
+		}
+		public.Board = append(public.Board, card)
+	}
+	return public, nil
+}
+
+// PublicStateOf - public state of hand played from root by history
+func PublicStateOf(state games.PokerGameState, history []acting.Action) PublicState {
+	public := PublicState{Board: append([]cards.Card{}, state.Table().Cards...)}
+	for _, action := range history {
+		switch action.Name() {
+		case acting.DealPrivateCards:
+		case acting.DealPublicCards:
+			public.Betting += "/"
+		default:
+			public.Betting += string(notation.ActionChar(action.Name()))
+		}
+	}
+	if !state.IsTerminal() && state.CurrentActor().GetID() == acting.ChanceId && state.Round() != rounds.Start {
+		// betting round is over, public card is not dealt yet
+		public.Betting += "/"
+	}
+	return public
+}
+
+func (public PublicState) String() string {
+	fields := []string{}
+	if public.Betting != "" {
+		fields = append(fields, public.Betting)
+	}
+	for _, card := range public.Board {
+		fields = append(fields, cards.FormatCard(card))
+	}
+	if len(fields) == 0 {
+		return notation.Root
+	}
+	return strings.Join(fields, " ")
+}
+
+// Deals - public state replayed from root for every deal of private cards consistent with it, reach probabilities follow strategy
+// (uniform in information sets it does not know)
+func Deals(root games.PokerGameState, strategy cfr.StrategyMap, public PublicState) ([]Deal, error) {
+	rootActions := root.Actions()
+	if root.IsTerminal() || root.CurrentActor().GetID() != acting.ChanceId || len(rootActions) == 0 {
+		return nil, errors.New("belief: root should deal private cards")
+	}
+	probabilities := games.ChanceProbabilities(root, rootActions)
+	deals := []Deal{}
+	for i, dealAction := range rootActions {
+		deal := Deal{State: root.Act(dealAction).(games.PokerGameState), Chance: float64(probabilities[i]),
+			Reach: map[acting.ActorID]float64{acting.PlayerA: 1, acting.PlayerB: 1}}
+		if deal.replay(strategy, public) {
+			deals = append(deals, deal)
+		}
+	}
+	if len(deals) == 0 {
+		return nil, fmt.Errorf("belief: public state %v can not be reached", public)
+	}
+	return deals, nil
+}
+
+// Compute - ranges of both players in public state of game (root of its game tree) when both follow strategy
+func Compute(root games.PokerGameState, strategy cfr.StrategyMap, public PublicState) (Belief, error) {
+	deals, err := Deals(root, strategy, public)
+	if err != nil {
+		return Belief{}, err
+	}
+	return FromDeals(public, deals)
+}
+
+// FromDeals - ranges of both players given all deals of public state
+func FromDeals(public PublicState, deals []Deal) (Belief, error) {
+	belief := Belief{Public: public, Ranges: map[acting.ActorID]map[cards.Card]float64{acting.PlayerA: {}, acting.PlayerB: {}}}
+	for _, deal := range deals {
+		belief.Probability += deal.Chance * deal.Reach[acting.PlayerA] * deal.Reach[acting.PlayerB]
+	}
+	if belief.Probability == 0 {
+		return belief, fmt.Errorf("belief: strategy never reaches public state %v", public)
+	}
+	for _, deal := range deals {
+		probability := deal.Chance * deal.Reach[acting.PlayerA] * deal.Reach[acting.PlayerB] / belief.Probability
+		if probability == 0 {
+			continue
+		}
+		for _, player := range []acting.ActorID{acting.PlayerA, acting.PlayerB} {
+			belief.Ranges[player][*deal.State.PrivateCard(player)] += probability
+		}
+	}
+	return belief, nil
+}
+
+// Cards - cards of player range, strongest first
+func (belief Belief) Cards(player acting.ActorID) []cards.Card {
+	playerCards := []cards.Card{}
+	for card := range belief.Ranges[player] {
+		playerCards = append(playerCards, card)
+	}
+	sort.Slice(playerCards, func(i, j int) bool {
+		rank, otherRank := cards.CardSymbol2Int(playerCards[i].Symbol), cards.CardSymbol2Int(playerCards[j].Symbol)
+		if rank != otherRank {
+			return rank > otherRank
+		}
+		return playerCards[i].String() < playerCards[j].String()
+	})
+	return playerCards
+}
+
+// Write - ranges as text, one line per player
+func (belief Belief) Write(w io.Writer) {
+	fmt.Fprintf(w, "public state %v (reached with probability %.4f)\n", belief.Public, belief.Probability)
+	for _, player := range []acting.ActorID{acting.PlayerA, acting.PlayerB} {
+		fields := []string{}
+		for _, card := range belief.Cards(player) {
+			fields = append(fields, fmt.Sprintf("%v %.3f", card, belief.Ranges[player][card]))
+		}
+		fmt.Fprintf(w, "  player %v: %v\n", acting.PlayerName(player), strings.Join(fields, ", "))
+	}
+}
+
+// replay - plays public state in the deal, false when it is not possible
+func (deal *Deal) replay(strategy cfr.StrategyMap, public PublicState) bool {
+	dealt := 0
+	for i := 0; i < len(public.Betting); i++ {
+		state := deal.State
+		if state.IsTerminal() {
+			return false
+		}
+		chance := state.CurrentActor().GetID() == acting.ChanceId
+		if public.Betting[i] == '/' {
+			if !chance {
+				return false
+			}
+			if dealt == len(public.Board) {
+				// public card is not dealt yet
+				return i == len(public.Betting)-1
+			}
+			if deal.State = games.DealPublicCard(state, public.Board[dealt]); deal.State == nil {
+				return false
+			}
+			dealt++
+			continue
+		}
+		if chance {
+			return false
+		}
+		actions := state.Actions()
+		probabilities := cfr.StrategyProbabilities(strategy, state.InformationSet(), actions)
+		found := false
+		for j, action := range actions {
+			if notation.ActionChar(action.Name()) == public.Betting[i] {
+				deal.Reach[state.CurrentActor().GetID()] *= probabilities[j]
+				deal.State = state.Act(action).(games.PokerGameState)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return dealt == len(public.Board)
+}
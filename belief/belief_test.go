@@ -0,0 +1,105 @@
+package belief
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/kuhn"
+	"github.com/int8/go-counterfactual-regret-minimization/games/rhodeisland"
+)
+
+func TestRangesFollowStrategy(t *testing.T) {
+	root := kuhn.NewRoot(100.)
+	// player A bets with king only
+	strategy := cfr.NewStrategyMap()
+	for _, deal := range root.Actions() {
+		state := root.Act(deal).(games.PokerGameState)
+		if *state.PrivateCard(acting.PlayerA) == cards.KingHearts {
+			strategy.Value[state.InformationSet()] = map[acting.ActionName]float32{acting.Bet: 1}
+		} else {
+			strategy.Value[state.InformationSet()] = map[acting.ActionName]float32{acting.Check: 1}
+		}
+	}
+
+	afterBet, err := Compute(root, strategy, PublicState{Betting: "r"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(afterBet.Probability-1./3) > 1e-6 || afterBet.Ranges[acting.PlayerA][cards.KingHearts] != 1 {
+		t.Errorf("Bet should reveal king of player A, got %v", afterBet.Ranges[acting.PlayerA])
+	}
+	if math.Abs(afterBet.Ranges[acting.PlayerB][cards.QueenHearts]-0.5) > 1e-6 || afterBet.Ranges[acting.PlayerB][cards.KingHearts] != 0 {
+		t.Errorf("Player B should hold queen or jack, got %v", afterBet.Ranges[acting.PlayerB])
+	}
+
+	afterCheck, err := Compute(root, strategy, PublicState{Betting: "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(afterCheck.Ranges[acting.PlayerB][cards.KingHearts]-0.5) > 1e-6 {
+		t.Errorf("Player B holds king in half of hands player A checks, got %v", afterCheck.Ranges[acting.PlayerB])
+	}
+	buffer := &bytes.Buffer{}
+	afterCheck.Write(buffer)
+	if !strings.Contains(buffer.String(), "player A: ♥Q 0.500, ♥J 0.500") {
+		t.Errorf("Ranges should be written strongest card first, got:\n%v", buffer.String())
+	}
+
+	if _, err := Compute(root, strategy, PublicState{Betting: "rr"}); err == nil {
+		t.Error("Impossible betting should be rejected")
+	}
+	if _, err := Compute(root, strategy, PublicState{Betting: "rcc"}); err == nil {
+		t.Error("Betting after the hand is over should be rejected")
+	}
+}
+
+func TestPublicCardsAreExcludedFromRanges(t *testing.T) {
+	defer func(maxRaises int) { rhodeisland.MaxRaises = maxRaises }(rhodeisland.MaxRaises)
+	rhodeisland.MaxRaises = 1
+	root := rhodeisland.NewRoot(100., cards.CreateLimitedDeck(cards.King, true))
+
+	public, err := ParsePublicState("rc/r Kh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if public.String() != "rc/r Kh" {
+		t.Errorf("Public state should be formatted back unchanged, got %v", public)
+	}
+	uniform, err := Compute(root, cfr.NewStrategyMap(), public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, player := range []acting.ActorID{acting.PlayerA, acting.PlayerB} {
+		if _, ok := uniform.Ranges[player][cards.KingHearts]; ok || len(uniform.Ranges[player]) != 7 {
+			t.Errorf("Public card can not be held by player %v, got %v", player, uniform.Ranges[player])
+		}
+		for card, probability := range uniform.Ranges[player] {
+			if math.Abs(probability-1./7) > 1e-6 {
+				t.Errorf("Uniform strategy should not change ranges, %v has probability %v", card, probability)
+			}
+		}
+	}
+
+	history := []acting.Action{root.Actions()[0]}
+	state := root.Act(history[0])
+	for _, name := range []acting.ActionName{acting.Bet, acting.Call} {
+		for _, action := range state.Actions() {
+			if action.Name() == name {
+				history = append(history, action)
+				state = state.Act(action)
+			}
+		}
+	}
+	if public := PublicStateOf(state.(games.PokerGameState), history); public.String() != "rc/" {
+		t.Errorf("Public state should be closed after bet and call, got %v", public)
+	}
+	if _, err := ParsePublicState("rc/r Zz"); err == nil {
+		t.Error("Invalid cards should be rejected")
+	}
+}
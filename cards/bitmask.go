@@ -0,0 +1,123 @@
+package cards
+
+import (
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"time"
+)
+
+// CompactCard - index of a card in allCards (0-51): 13 ranks from 2 to Ace of hearts, spades, clubs and diamonds
+type CompactCard uint8
+
+// NumberOfCards - number of cards in the full deck, CompactCard of cards outside of it
+const NumberOfCards = 52
+
+// suitOrder - position of suit in allCards, indexed by CardSuit2Int
+var suitOrder = [5]int{-1, 0, 3, 1, 2}
+
+// ToCompact - index of card, second value is false for cards outside of the full deck (e.g. NoCard)
+func ToCompact(card Card) (CompactCard, bool) {
+	rank, suit := int(CardSymbol2Int(card.Symbol))-1, cardSuit2Int(card.Suit)
+	if rank < 0 || rank > 12 || suit < 1 || suit > 4 {
+		return NumberOfCards, false
+	}
+	return CompactCard(suitOrder[suit]*13 + rank), true
+}
+
+// Card - card of the index, the same pointer as the package level card (e.g. &AceHearts)
+func (c CompactCard) Card() *Card {
+	if c >= NumberOfCards {
+		return &NoCard
+	}
+	return allCards[c]
+}
+
+// Rank - 2-14, 11-14 stand for J Q K A
+func (c CompactCard) Rank() int {
+	return int(c)%13 + 2
+}
+
+func (c CompactCard) String() string {
+	if c >= NumberOfCards {
+		return fmt.Sprintf("CompactCard(%d)", uint8(c))
+	}
+	return c.Card().String()
+}
+
+// BitmaskDeck - deck of cards stored as bits of CompactCard indices, cheap to clone and listed in fixed order
+type BitmaskDeck struct {
+	Cards uint64
+}
+
+// CreateBitmaskDeck - deck of cards from minCardSymbol up, full deck for C2
+func CreateBitmaskDeck(minCardSymbol CardSymbol, shuffleInitially bool) *BitmaskDeck {
+	deck := &BitmaskDeck{}
+	for i, card := range allCards {
+		if cardNameCompare(card.Symbol, minCardSymbol) >= 0 {
+			deck.Cards |= 1 << uint(i)
+		}
+	}
+	deck.Shuffle()
+	return deck
+}
+
+// BitmaskDeckOf - deck of given cards
+func BitmaskDeckOf(cards ...*Card) *BitmaskDeck {
+	deck := &BitmaskDeck{}
+	for _, card := range cards {
+		deck.AddCard(card)
+	}
+	return deck
+}
+
+func (d *BitmaskDeck) Shuffle() {
+	rand.Seed(time.Now().UTC().UnixNano())
+}
+
+// RemoveCard - cards outside of the full deck are ignored
+func (d *BitmaskDeck) RemoveCard(card *Card) {
+	if index, ok := ToCompact(*card); ok {
+		d.Cards &^= 1 << uint(index)
+	}
+}
+
+// AddCard - cards outside of the full deck are ignored
+func (d *BitmaskDeck) AddCard(card *Card) {
+	if index, ok := ToCompact(*card); ok {
+		d.Cards |= 1 << uint(index)
+	}
+}
+
+// Contains - true when card is still in the deck
+func (d *BitmaskDeck) Contains(card *Card) bool {
+	index, ok := ToCompact(*card)
+	return ok && d.Cards&(1<<uint(index)) != 0
+}
+
+func (d *BitmaskDeck) CardsLeft() int {
+	return bits.OnesCount64(d.Cards)
+}
+
+// RemainingCards - cards in order of allCards, like FullDeck and LimitedDeck
+func (d *BitmaskDeck) RemainingCards() []*Card {
+	cards := make([]*Card, 0, d.CardsLeft())
+	for left := d.Cards; left != 0; left &= left - 1 {
+		cards = append(cards, allCards[bits.TrailingZeros64(left)])
+	}
+	return cards
+}
+
+func (d *BitmaskDeck) Clone() Deck {
+	return &BitmaskDeck{d.Cards}
+}
+
+func (d *BitmaskDeck) DealNextRandomCard() *Card {
+	left := d.Cards
+	for i := rand.Intn(d.CardsLeft()); i > 0; i-- {
+		left &= left - 1
+	}
+	card := allCards[bits.TrailingZeros64(left)]
+	d.RemoveCard(card)
+	return card
+}
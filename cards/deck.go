@@ -10,6 +10,8 @@ import (
 type Deck interface {
 	Shuffle()
 	RemoveCard(card *Card)
+	// AddCard - puts removed card back
+	AddCard(card *Card)
 	CardsLeft() int
 	Clone() Deck
 	RemainingCards() []*Card
@@ -50,14 +52,37 @@ func (d *FullDeck) RemoveCard(card *Card) {
 	delete(d.Cards, card)
 }
 
+func (d *FullDeck) AddCard(card *Card) {
+	d.Cards[card] = true
+}
+
 func (d *FullDeck) CardsLeft() int {
 	return len(d.Cards)
 }
 
 func (d *FullDeck) RemainingCards() []*Card {
-	cards := make([]*Card, 0, len(d.Cards))
-	for card := range d.Cards {
-		cards = append(cards, card)
+	return orderedCards(d.Cards)
+}
+
+// orderedCards - cards of the set in order of allCards (cards outside of it follow), so that chance actions built from the
+// deck come in the same order every time
+func orderedCards(set map[*Card]bool) []*Card {
+	cards := make([]*Card, 0, len(set))
+	for _, card := range allCards {
+		if set[card] {
+			cards = append(cards, card)
+		}
+	}
+	if len(cards) < len(set) {
+		known := make(map[*Card]bool, len(cards))
+		for _, card := range cards {
+			known[card] = true
+		}
+		for card := range set {
+			if !known[card] {
+				cards = append(cards, card)
+			}
+		}
 	}
 	return cards
 }
@@ -109,16 +134,16 @@ func (d *LimitedDeck) RemoveCard(card *Card) {
 	delete(d.Cards, card)
 }
 
+func (d *LimitedDeck) AddCard(card *Card) {
+	d.Cards[card] = true
+}
+
 func (d *LimitedDeck) CardsLeft() int {
 	return len(d.Cards)
 }
 
 func (d *LimitedDeck) RemainingCards() []*Card {
-	cards := make([]*Card, 0, len(d.Cards))
-	for card := range d.Cards {
-		cards = append(cards, card)
-	}
-	return cards
+	return orderedCards(d.Cards)
 }
 
 func (d *LimitedDeck) Clone() Deck {
@@ -129,6 +154,16 @@ func (d *LimitedDeck) Clone() Deck {
 	return &LimitedDeck{cards}
 }
 
+// Contains - card is one of cardList
+func Contains(cardList []Card, card Card) bool {
+	for _, c := range cardList {
+		if c == card {
+			return true
+		}
+	}
+	return false
+}
+
 func (c Card) String() string {
 	return fmt.Sprintf("%v%v", c.Suit, c.Symbol)
 }
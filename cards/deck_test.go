@@ -52,3 +52,106 @@ func TestIfAllCardsAreDealt(t *testing.T) {
 		}
 	}
 }
+
+func TestCompactCardsFollowAllCards(t *testing.T) {
+	for i, card := range allCards {
+		compact, ok := ToCompact(*card)
+		if !ok || int(compact) != i || compact.Card() != card {
+			t.Errorf("%v should be compact card %v, got %v", card, i, compact)
+		}
+		if compact.Rank() != int(CardSymbol2Int(card.Symbol))+1 {
+			t.Errorf("%v should be of rank %v, got %v", card, CardSymbol2Int(card.Symbol)+1, compact.Rank())
+		}
+	}
+	if _, ok := ToCompact(NoCard); ok {
+		t.Error("NoCard should not have compact representation")
+	}
+}
+
+func TestBitmaskDeckFollowsMapDecks(t *testing.T) {
+	for _, symbol := range []CardSymbol{C2, C10, King} {
+		bitmask, limited := CreateBitmaskDeck(symbol, true), CreateLimitedDeck(symbol, true)
+		for len(limited.RemainingCards()) > 0 {
+			expected, remaining := limited.RemainingCards(), bitmask.RemainingCards()
+			if bitmask.CardsLeft() != limited.CardsLeft() || len(remaining) != len(expected) {
+				t.Fatalf("Bitmask deck from %v should have %v cards left, got %v", symbol, limited.CardsLeft(), bitmask.CardsLeft())
+			}
+			for i := range expected {
+				if remaining[i] != expected[i] {
+					t.Fatalf("Bitmask deck from %v should list %v as card %v, got %v", symbol, expected[i], i, remaining[i])
+				}
+			}
+			card := expected[len(expected)/2]
+			clone := bitmask.Clone()
+			bitmask.RemoveCard(card)
+			limited.RemoveCard(card)
+			if bitmask.Contains(card) || !clone.(*BitmaskDeck).Contains(card) {
+				t.Fatalf("%v should be removed from the deck but not from its clone", card)
+			}
+		}
+	}
+
+	deck := CreateBitmaskDeck(C2, true)
+	deck.RemoveCard(&AceClubs)
+	deck.AddCard(&AceClubs)
+	deck.RemoveCard(&NoCard)
+	if deck.CardsLeft() != 52 || !deck.Contains(&AceClubs) {
+		t.Errorf("Card added back should be in the deck, %v cards left", deck.CardsLeft())
+	}
+	dealt := map[*Card]bool{}
+	for deck.CardsLeft() > 0 {
+		dealt[deck.DealNextRandomCard()] = true
+	}
+	if len(dealt) != 52 {
+		t.Errorf("All 52 cards should be dealt, got %v", len(dealt))
+	}
+}
+
+func TestCardNotationRoundTrip(t *testing.T) {
+	for _, card := range allCards {
+		parsed, err := ParseCard(FormatCard(*card))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed != *card {
+			t.Errorf("%v should be parsed back from %v, got %v", card, FormatCard(*card), parsed)
+		}
+	}
+	if FormatCard(AceHearts) != "Ah" || FormatCard(C10Diamonds) != "Td" || FormatCard(C2Clubs) != "2c" || FormatCard(NoCard) != "??" {
+		t.Errorf("Cards should be written as rank and suit, got %v %v %v %v", FormatCard(AceHearts), FormatCard(C10Diamonds),
+			FormatCard(C2Clubs), FormatCard(NoCard))
+	}
+	for _, text := range []string{"", "A", "Ahh", "1h", "10h", "ah", "AH", "Ax", "??", "♥A"} {
+		if _, err := ParseCard(text); err == nil {
+			t.Errorf("Card %q should be rejected", text)
+		}
+	}
+}
+
+func TestHandAndBoardNotation(t *testing.T) {
+	for _, text := range []string{"AhKd", "Ah Kd", "Ah,Kd", " Ah, Kd "} {
+		hand, err := ParseCards(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hand) != 2 || hand[0] != AceHearts || hand[1] != KingDiamonds {
+			t.Errorf("%q should be parsed as [Ah Kd], got %v", text, hand)
+		}
+	}
+	board := []Card{QueenSpades, C10Clubs, C2Hearts}
+	parsed, err := ParseCards(FormatCards(board))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if FormatCards(board) != "QsTc2h" || len(parsed) != 3 || FormatCards(parsed) != "QsTc2h" {
+		t.Errorf("Board should be written as QsTc2h and parsed back, got %v and %v", FormatCards(board), parsed)
+	}
+	if empty, err := ParseCards(""); err != nil || len(empty) != 0 {
+		t.Errorf("Empty board should be parsed with no cards, got %v %v", empty, err)
+	}
+	for _, text := range []string{"AhK", "Ah Kx", "AhAh", "Ah ah", "Ah;Kd"} {
+		if _, err := ParseCards(text); err == nil {
+			t.Errorf("Cards %q should be rejected", text)
+		}
+	}
+}
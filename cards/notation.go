@@ -0,0 +1,68 @@
+package cards
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rankChars - ranks from 2 to Ace in standard notation, T stands for 10
+const rankChars = "23456789TJQKA"
+
+// suitChars - suits in standard notation, in order of suitOrder
+const suitChars = "hscd"
+
+// ParseCard - card in standard two character notation: rank (2-9, T, J, Q, K or A) followed by suit (h, d, s or c), e.g. Ah or Td
+func ParseCard(text string) (Card, error) {
+	if len(text) != 2 {
+		return Card{}, fmt.Errorf("cards: invalid card %q, rank and suit expected", text)
+	}
+	rank, suit := strings.IndexByte(rankChars, text[0]), strings.IndexByte(suitChars, text[1])
+	if rank < 0 {
+		return Card{}, fmt.Errorf("cards: invalid rank %q of card %q", text[0], text)
+	}
+	if suit < 0 {
+		return Card{}, fmt.Errorf("cards: invalid suit %q of card %q", text[1], text)
+	}
+	return *allCards[suit*13+rank], nil
+}
+
+// ParseCards - hand or board of distinct cards, written one after another (AhKd) or separated with spaces or commas (Ah Kd)
+func ParseCards(text string) ([]Card, error) {
+	parsed := []Card{}
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		if len(field)%2 != 0 {
+			return nil, fmt.Errorf("cards: invalid cards %q, %q is not a sequence of cards", text, field)
+		}
+		for ; field != ""; field = field[2:] {
+			card, err := ParseCard(field[:2])
+			if err != nil {
+				return nil, err
+			}
+			for _, previous := range parsed {
+				if previous == card {
+					return nil, fmt.Errorf("cards: %v appears twice in %q", FormatCard(card), text)
+				}
+			}
+			parsed = append(parsed, card)
+		}
+	}
+	return parsed, nil
+}
+
+// FormatCard - card in standard notation read by ParseCard, ?? for cards outside of the full deck (e.g. NoCard)
+func FormatCard(card Card) string {
+	index, ok := ToCompact(card)
+	if !ok {
+		return "??"
+	}
+	return rankChars[index%13:index%13+1] + suitChars[index/13:index/13+1]
+}
+
+// FormatCards - cards written one after another (e.g. AhKd), read back by ParseCards
+func FormatCards(cardList []Card) string {
+	text := ""
+	for _, card := range cardList {
+		text += FormatCard(card)
+	}
+	return text
+}
@@ -31,3 +31,13 @@ func CardSymbol2Int(name1 CardSymbol) int8 {
 	}
 	return result
 }
+
+func cardSuit2Int(suit CardSuit) int {
+	result := 0
+	for i := 0; i < 3; i++ {
+		if suit[i] {
+			result += 1 << uint(i)
+		}
+	}
+	return result
+}
@@ -0,0 +1,135 @@
+package cfr
+
+import (
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+)
+
+type reachedState struct {
+	state games.GameState
+	reach float64
+}
+
+type bestResponse struct {
+	player   acting.ActorID
+	strategy StrategyMap
+	// abstraction - maps information sets of opponent to the ones of strategy, nil for none
+	abstraction games.Abstraction
+	states      map[games.InformationSet][]reachedState
+	actions     map[games.InformationSet]acting.Action
+}
+
+// BestResponseValue - expected payoff of player best responding to the strategy of his opponent
+func BestResponseValue(root games.GameState, strategy StrategyMap, player acting.ActorID) float32 {
+	return BestResponseValueWithAbstraction(root, strategy, nil, player)
+}
+
+// BestResponseValueWithAbstraction - expected payoff of player best responding in the real game to the strategy computed for abstraction
+func BestResponseValueWithAbstraction(root games.GameState, strategy StrategyMap, abstraction games.Abstraction, player acting.ActorID) float32 {
+	br := &bestResponse{player: player, strategy: strategy, abstraction: abstraction,
+		states: map[games.InformationSet][]reachedState{}, actions: map[games.InformationSet]acting.Action{}}
+	br.collect(root, 1.)
+	return float32(br.value(root))
+}
+
+// Exploitability - average gain of both players best responding to the strategy, zero for Nash equilibrium
+func Exploitability(root games.GameState, strategy StrategyMap) float32 {
+	return ExploitabilityWithAbstraction(root, strategy, nil)
+}
+
+// ExploitabilityWithAbstraction - exploitability in the real game of strategy computed for abstract information sets
+// (e.g. by imperfect recall routine), best responding player is not limited by the abstraction
+func ExploitabilityWithAbstraction(root games.GameState, strategy StrategyMap, abstraction games.Abstraction) float32 {
+	return (BestResponseValueWithAbstraction(root, strategy, abstraction, acting.PlayerA) +
+		BestResponseValueWithAbstraction(root, strategy, abstraction, acting.PlayerB)) / 2
+}
+
+// collect - groups states of best responding player by information sets, reach excludes his own actions
+func (br *bestResponse) collect(state games.GameState, reach float64) {
+	if state.IsTerminal() || reach == 0 {
+		return
+	}
+	actions := state.Actions()
+	probabilities := br.probabilities(state, actions)
+	if state.CurrentActor().GetID() == br.player {
+		infSet := state.InformationSet()
+		br.states[infSet] = append(br.states[infSet], reachedState{state, reach})
+	}
+	for i, action := range actions {
+		br.collect(state.Act(action), reach*probabilities[i])
+	}
+}
+
+func (br *bestResponse) value(state games.GameState) float64 {
+	if state.IsTerminal() {
+		return float64(br.player) * float64(state.Evaluate())
+	}
+	if state.CurrentActor().GetID() == br.player {
+		return br.value(state.Act(br.bestAction(state.InformationSet())))
+	}
+	actions := state.Actions()
+	value := 0.
+	for i, probability := range br.probabilities(state, actions) {
+		if probability > 0 {
+			value += probability * br.value(state.Act(actions[i]))
+		}
+	}
+	return value
+}
+
+func (br *bestResponse) bestAction(infSet games.InformationSet) acting.Action {
+	if action, ok := br.actions[infSet]; ok {
+		return action
+	}
+	states := br.states[infSet]
+	var best acting.Action
+	bestValue := 0.
+	for i, action := range states[0].state.Actions() {
+		value := 0.
+		for _, reached := range states {
+			value += reached.reach * br.value(reached.state.Act(reached.state.Actions()[i]))
+		}
+		if best == nil || value > bestValue {
+			best, bestValue = action, value
+		}
+	}
+	br.actions[infSet] = best
+	return best
+}
+
+// probabilities - probabilities of actions for chance and opponent, 1 for best responding player
+func (br *bestResponse) probabilities(state games.GameState, actions []acting.Action) []float64 {
+	switch state.CurrentActor().GetID() {
+	case acting.ChanceId:
+		probabilities := make([]float64, len(actions))
+		for i, probability := range games.ChanceProbabilities(state, actions) {
+			probabilities[i] = float64(probability)
+		}
+		return probabilities
+	case br.player:
+		probabilities := make([]float64, len(actions))
+		for i := range probabilities {
+			probabilities[i] = 1.
+		}
+		return probabilities
+	}
+	return StrategyProbabilities(br.strategy, abstractInformationSet(state, br.abstraction), actions)
+}
+
+// StrategyProbabilities - normalized probabilities of actions of information set under strategy, uniform for unseen ones
+func StrategyProbabilities(strategy StrategyMap, infSet games.InformationSet, actions []acting.Action) []float64 {
+	probabilities := make([]float64, len(actions))
+	sum := 0.
+	for i, action := range actions {
+		probabilities[i] = float64(strategy.Value[infSet][action.Name()])
+		sum += probabilities[i]
+	}
+	for i := range probabilities {
+		if sum > 0 {
+			probabilities[i] /= sum
+		} else {
+			probabilities[i] = 1. / float64(len(actions))
+		}
+	}
+	return probabilities
+}
@@ -16,6 +16,11 @@ func newStrategyMap() StrategyMap {
 	return StrategyMap{Value: map[games.InformationSet]map[acting.ActionName]float32{}, mutex: &sync.Mutex{}}
 }
 
+// NewStrategyMap - empty strategy map, for strategies computed outside of ComputingRoutine
+func NewStrategyMap() StrategyMap {
+	return newStrategyMap()
+}
+
 func (sm StrategyMap) initIfZero(infSet games.InformationSet) {
 	if _, ok := sm.Value[infSet]; !ok {
 		sm.Value[infSet] = map[acting.ActionName]float32{}
@@ -43,14 +48,11 @@ func (sm StrategyMap) getValue(infSet games.InformationSet, action acting.Action
 	return sm.Value[infSet][action]
 }
 
+// getKeys - actions of information set in fixed order, sums over them do not depend on map iteration order
 func (sm StrategyMap) getKeys(infSet games.InformationSet) []acting.ActionName {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
-	actions := []acting.ActionName{}
-	for action := range sm.Value[infSet] {
-		actions = append(actions, action)
-	}
-	return actions
+	return sortedActions(sm.Value[infSet])
 }
 
 func (sm StrategyMap) nrOfInfSets() int {
@@ -64,17 +66,43 @@ func (sm StrategyMap) sumValuesForInformationSet(infSet games.InformationSet) fl
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 	regretSum := float32(0.)
-	for _, k := range sm.Value[infSet] {
-		regretSum += maxFloat32(k, 0.0)
+	for _, action := range sortedActions(sm.Value[infSet]) {
+		regretSum += maxFloat32(sm.Value[infSet][action], 0.0)
 	}
 	return regretSum
 }
 
+// Normalize - action probabilities of a strategy (or strategy sum) entry summing to one, uniform when all are zero
+func Normalize(actions map[acting.ActionName]float32) map[acting.ActionName]float64 {
+	probabilities := make(map[acting.ActionName]float64, len(actions))
+	sum := 0.
+	for _, value := range actions {
+		sum += float64(value)
+	}
+	for name, value := range actions {
+		if sum > 0 {
+			probabilities[name] = float64(value) / sum
+		} else {
+			probabilities[name] = 1. / float64(len(actions))
+		}
+	}
+	return probabilities
+}
+
 type ComputingRoutine struct {
-	sigmaSum   StrategyMap
-	sigma      StrategyMap
-	regretsSum StrategyMap
-	root       games.GameState
+	sigmaSum    StrategyMap
+	sigma       StrategyMap
+	regretsSum  StrategyMap
+	root        games.GameState
+	abstraction games.Abstraction
+	// imperfectRecall - sigma of information sets visited in an iteration is updated once the iteration is over
+	imperfectRecall bool
+	visited         map[games.InformationSet]bool
+	visitedMutex    *sync.Mutex
+	// pruning - subtrees neither player reaches are skipped, see SetRegretPruning
+	pruning bool
+	// seeds - source of seeds of tree-parallel tasks
+	seeds *rand.Rand
 }
 
 func CreateComputingRoutine(root games.GameState) *ComputingRoutine {
@@ -82,32 +110,144 @@ func CreateComputingRoutine(root games.GameState) *ComputingRoutine {
 	return &routine
 }
 
-func (routine *ComputingRoutine) cumulateCfrRegret(infSet games.InformationSet, action acting.ActionName, value float32) {
+// CreateAbstractedComputingRoutine - routine computing strategy for information sets mapped by abstraction (see ExpandStrategy)
+func CreateAbstractedComputingRoutine(root games.GameState, abstraction games.Abstraction) *ComputingRoutine {
+	routine := CreateComputingRoutine(root)
+	routine.abstraction = abstraction
+	return routine
+}
+
+// CreateImperfectRecallComputingRoutine - routine for abstractions forgetting part of the history (e.g. card buckets of earlier rounds).
+// Abstract information set can be reached several times in one iteration, its strategy changes only between iterations.
+// Computed strategy is keyed by abstract information sets, see ExploitabilityWithAbstraction.
+func CreateImperfectRecallComputingRoutine(root games.GameState, abstraction games.Abstraction) *ComputingRoutine {
+	routine := CreateAbstractedComputingRoutine(root, abstraction)
+	routine.imperfectRecall = true
+	routine.visited = map[games.InformationSet]bool{}
+	routine.visitedMutex = &sync.Mutex{}
+	return routine
+}
+
+// ExpandStrategy - strategy for every information set of the game, computed for abstract information sets of expansion
+func ExpandStrategy(strategy StrategyMap, expansion games.Expansion) StrategyMap {
+	expanded := newStrategyMap()
+	for abstract, actions := range strategy.Value {
+		for _, infSet := range expansion.Expand(abstract) {
+			expanded.Value[infSet] = map[acting.ActionName]float32{}
+			for action, value := range actions {
+				expanded.Value[infSet][action] = value
+			}
+		}
+	}
+	return expanded
+}
+
+// ExpandAbstractStrategy - strategy for every information set of the game tree, computed for abstract information sets of
+// abstraction unable to list information sets it merges (e.g. card buckets), the whole tree is traversed
+func ExpandAbstractStrategy(root games.GameState, strategy StrategyMap, abstraction games.Abstraction) StrategyMap {
+	expanded := newStrategyMap()
+	var expand func(state games.GameState)
+	expand = func(state games.GameState) {
+		if state.IsTerminal() {
+			return
+		}
+		if state.CurrentActor().GetID() != acting.ChanceId {
+			infSet := state.InformationSet()
+			if _, done := expanded.Value[infSet]; !done {
+				if actions, ok := strategy.Value[abstraction.Abstract(infSet)]; ok {
+					expanded.Value[infSet] = map[acting.ActionName]float32{}
+					for action, value := range actions {
+						expanded.Value[infSet][action] = value
+					}
+				}
+			}
+		}
+		for _, action := range state.Actions() {
+			expand(state.Act(action))
+		}
+	}
+	expand(root)
+	return expanded
+}
+
+// SetRegretPruning - skip subtrees both players reach with zero probability, i.e. after actions of negative regret of both
+// of them. Regrets and strategy sums of such subtrees are weighted by zero reach, so pruning loses no update and the computed
+// strategy is the same as without it (up to chance sampling).
+func (routine *ComputingRoutine) SetRegretPruning(enabled bool) {
+	routine.pruning = enabled
+}
+
+func (routine *ComputingRoutine) informationSet(state games.GameState) games.InformationSet {
+	return abstractInformationSet(state, routine.abstraction)
+}
+
+// abstractInformationSet - information set of state as seen by strategy computed for abstraction (raw one when abstraction is nil)
+func abstractInformationSet(state games.GameState, abstraction games.Abstraction) games.InformationSet {
+	if abstraction == nil {
+		return state.InformationSet()
+	}
+	return abstraction.Abstract(state.InformationSet())
+}
+
+func (routine *ComputingRoutine) cumulateCfrRegret(delta *iterationDelta, infSet games.InformationSet, action acting.ActionName, value float32) {
+	if delta != nil {
+		delta.regrets.setValue(infSet, action, delta.regrets.getValue(infSet, action)+delta.weight*value)
+		return
+	}
 	currentValue := routine.regretsSum.getValue(infSet, action)
 	routine.regretsSum.setValue(infSet, action, currentValue + value)
 }
 
-func (routine *ComputingRoutine) cumulateSigma(infSet games.InformationSet, action acting.ActionName, value float32) {
+func (routine *ComputingRoutine) cumulateSigma(delta *iterationDelta, infSet games.InformationSet, action acting.ActionName, value float32) {
+	if delta != nil {
+		delta.sigmaSums.setValue(infSet, action, delta.sigmaSums.getValue(infSet, action)+delta.weight*value)
+		return
+	}
 	currentValue := routine.sigmaSum.getValue(infSet, action)
 	routine.sigmaSum.setValue(infSet, action, currentValue + value)
 }
 
+// ComputeNashEquilibriumViaCFR - runs iterations of chance sampling CFR, numThreads of them at a time on shared tables
+// (see ComputeNashEquilibriumViaTreeParallelCFR for deterministic parallel iterations), less than one thread means one
 func (routine *ComputingRoutine) ComputeNashEquilibriumViaCFR(iterations int, numThreads int) StrategyMap {
 
-	for i := 0; i < iterations / numThreads; i++ {
+	if numThreads < 1 {
+		numThreads = 1
+	}
+	for done := 0; done < iterations; done += numThreads {
+		threads := numThreads
+		if iterations-done < threads {
+			threads = iterations - done
+		}
 		group := &sync.WaitGroup{}
-		for j := 0; j < numThreads; j++ {
+		for j := 0; j < threads; j++ {
 			group.Add(1)
 			go func() {
-				routine.cfrUtilityRecursive(routine.root, 1, 1)
+				routine.cfrUtilityRecursive(traversalState(routine.root), 1, 1, nil)
 				group.Done()
 			}()
 		}
 		group.Wait()
+		if routine.imperfectRecall {
+			routine.updateVisitedSigma()
+		}
 	}
 	return routine.computeNashEquilibriumBasedOnStrategySum()
 }
 
+func (routine *ComputingRoutine) visit(infSet games.InformationSet) {
+	routine.visitedMutex.Lock()
+	defer routine.visitedMutex.Unlock()
+	routine.visited[infSet] = true
+}
+
+func (routine *ComputingRoutine) updateVisitedSigma() {
+	for infSet := range routine.visited {
+		routine.updateSigma(infSet)
+	}
+	routine.visited = map[games.InformationSet]bool{}
+}
+
 func (routine *ComputingRoutine) updateSigma(infSet games.InformationSet) {
 
 	regretSum := routine.regretsSum.sumValuesForInformationSet(infSet)
@@ -128,20 +268,27 @@ func (routine *ComputingRoutine) actionProbability(infSet games.InformationSet,
 	return routine.sigma.getValue(infSet, action)
 }
 
-func (routine *ComputingRoutine) cfrUtilityRecursive(state games.GameState, reachA float32, reachB float32) float32 {
+// cfrUtilityRecursive - expected payoff of player A in state, regrets and strategy sums are cumulated in delta unless it is nil.
+// State comes from traversalState: mutable one is moved to children and back in place.
+func (routine *ComputingRoutine) cfrUtilityRecursive(state games.GameState, reachA float32, reachB float32, delta *iterationDelta) float32 {
 
 	childrenStateUtilities := map[acting.ActionName]float32{}
 	if state.IsTerminal() {
 		return state.Evaluate()
 	}
+	if routine.pruning && reachA == 0 && reachB == 0 {
+		// value of the subtree is weighted by zero in every update above it
+		return 0
+	}
 
 	if state.CurrentActor().GetID() == acting.ChanceId {
-		actions := state.Actions()
-		action := actions[rand.Intn(len(actions))]
-		return routine.cfrUtilityRecursive(state.Act(action), reachA, reachB)
+		action := games.SampleChance(state, delta.random())
+		value := routine.cfrUtilityRecursive(act(state, action), reachA, reachB, delta)
+		undo(state)
+		return value
 	}
 
-	infSet := state.InformationSet()
+	infSet := routine.informationSet(state)
 	value := float32(0.0)
 	actions := state.Actions()
 	for _, action := range actions {
@@ -155,7 +302,8 @@ func (routine *ComputingRoutine) cfrUtilityRecursive(state games.GameState, reac
 			childReachB *= prob
 		}
 
-		childStateUtility := routine.cfrUtilityRecursive(state.Act(action), childReachA, childReachB)
+		childStateUtility := routine.cfrUtilityRecursive(act(state, action), childReachA, childReachB, delta)
+		undo(state)
 		value += prob * childStateUtility
 
 		childrenStateUtilities[action.Name()] = childStateUtility
@@ -171,26 +319,55 @@ func (routine *ComputingRoutine) cfrUtilityRecursive(state games.GameState, reac
 	for _, action := range actions {
 		if cfrReach > 0 {
 			actionCfrRegret := float32(state.CurrentActor().GetID()) * cfrReach * (childrenStateUtilities[action.Name()] - value)
-			routine.cumulateCfrRegret(infSet, action.Name(), actionCfrRegret)
+			routine.cumulateCfrRegret(delta, infSet, action.Name(), actionCfrRegret)
 		}
 		if reach > 0 {
-			routine.cumulateSigma(infSet, action.Name(), reach*routine.actionProbability(infSet, action.Name(), len(actions)))
+			routine.cumulateSigma(delta, infSet, action.Name(), reach*routine.actionProbability(infSet, action.Name(), len(actions)))
 		}
 	}
 
-	if cfrReach > 0 {
-		routine.updateSigma(infSet)
+	// sigma of tree-parallel iterations is updated once deltas are merged
+	if cfrReach > 0 && delta == nil {
+		if routine.imperfectRecall {
+			routine.visit(infSet)
+		} else {
+			routine.updateSigma(infSet)
+		}
 	}
 
 	return value
 }
 
+// traversalState - mutable copy of state when its game supports Apply and Undo (see games.Mutable), state itself otherwise
+func traversalState(state games.GameState) games.GameState {
+	if mutable, ok := state.(games.Mutable); ok {
+		return mutable.Mutable()
+	}
+	return state
+}
+
+// act - child of state reached with action, mutable state is moved in place
+func act(state games.GameState, action acting.Action) games.GameState {
+	if mutable, ok := state.(games.MutableGameState); ok {
+		mutable.Apply(action)
+		return mutable
+	}
+	return state.Act(action)
+}
+
+// undo - moves mutable state back after act
+func undo(state games.GameState) {
+	if mutable, ok := state.(games.MutableGameState); ok {
+		mutable.Undo()
+	}
+}
+
 func (routine *ComputingRoutine) computeNashEquilibriumBasedOnStrategySum() StrategyMap {
 	nashEquilibrium := newStrategyMap()
 	for infSet := range routine.sigmaSum.Value {
 		nashEquilibrium.Value[infSet] = map[acting.ActionName]float32{}
 		infSetSigmaSum := float32(0.0)
-		for action := range routine.sigmaSum.Value[infSet] {
+		for _, action := range sortedActions(routine.sigmaSum.Value[infSet]) {
 			infSetSigmaSum += routine.sigmaSum.Value[infSet][action]
 		}
 
@@ -201,7 +378,8 @@ func (routine *ComputingRoutine) computeNashEquilibriumBasedOnStrategySum() Stra
 	return nashEquilibrium
 }
 
-func computeUtility(state games.GameState, sigma StrategyMap) float32 {
+// computeUtility - expected payoff of player A when both players follow sigma, computed for abstraction (nil for none)
+func computeUtility(state games.GameState, sigma StrategyMap, abstraction games.Abstraction) float32 {
 
 	if state.IsTerminal() {
 		return state.Evaluate()
@@ -209,19 +387,20 @@ func computeUtility(state games.GameState, sigma StrategyMap) float32 {
 
 	if state.CurrentActor().GetID() == acting.ChanceId {
 		actions := state.Actions()
+		probabilities := games.ChanceProbabilities(state, actions)
 		eval := float32(0.0)
-		for _, action := range actions {
-			eval += (1. / float32(len(actions))) * computeUtility(state.Act(action), sigma)
+		for i, action := range actions {
+			eval += probabilities[i] * computeUtility(state.Act(action), sigma, abstraction)
 		}
 		return eval
 	}
 
-	infSet := state.InformationSet()
+	infSet := abstractInformationSet(state, abstraction)
 
 	value := float32(0.0)
 	actions := state.Actions()
 	for _, action := range actions {
-		value += sigma.Value[infSet][action.Name()] * computeUtility(state.Act(action), sigma)
+		value += sigma.Value[infSet][action.Name()] * computeUtility(state.Act(action), sigma, abstraction)
 	}
 
 	return value
@@ -1,10 +1,16 @@
 package cfr
 
 import (
+	"bytes"
 	"github.com/int8/go-counterfactual-regret-minimization/acting"
 	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/efg"
 	"github.com/int8/go-counterfactual-regret-minimization/games/kuhn"
 	"github.com/int8/go-counterfactual-regret-minimization/games/rhodeisland"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -13,13 +19,184 @@ func TestKuhnPokerNashEquilibriumMatchesExpectedUtility(t *testing.T) {
 	root := createRootForKuhnPokerTest(1000., 1000.)
 	routine := CreateComputingRoutine(root)
 	ne := routine.ComputeNashEquilibriumViaCFR(50000, 1)
-	utility := computeUtility(root, ne)
+	utility := computeUtility(root, ne, nil)
 
 	if utility > -0.05 || utility < -0.06 {
 		t.Error("Unless you are extremely unlucky, something is wrong with your CFR implementation")
 	}
 }
 
+func TestKuhnPokerExploitability(t *testing.T) {
+
+	root := createRootForKuhnPokerTest(1000., 1000.)
+	uniform := Exploitability(root, newStrategyMap())
+	routine := CreateComputingRoutine(root)
+	ne := routine.ComputeNashEquilibriumViaCFR(50000, 1)
+	exploitability := Exploitability(root, ne)
+
+	if uniform < 0.4 {
+		t.Errorf("Uniformly random strategy should be highly exploitable, got %v", uniform)
+	}
+	if exploitability < 0 || exploitability > 0.01 {
+		t.Errorf("Nash equilibrium approximation should be barely exploitable, got %v", exploitability)
+	}
+	if value := BestResponseValue(root, ne, acting.PlayerB); value < 1./18-0.01 {
+		t.Errorf("Player B best responding should win at least game value, got %v", value)
+	}
+}
+
+func TestKuhnPokerCounterfactualValues(t *testing.T) {
+
+	root := createRootForKuhnPokerTest(1000., 1000.)
+	uniform := CounterfactualValues(root, newStrategyMap())
+	ne := CounterfactualValues(root, CreateComputingRoutine(root).ComputeNashEquilibriumViaCFR(50000, 1))
+
+	if len(uniform) != 12 || len(ne) != 12 {
+		t.Fatalf("Kuhn Poker has 12 information sets, got %v and %v", len(uniform), len(ne))
+	}
+	maxRegret := func(values []InformationSetValue) float64 {
+		result := math.Inf(-1)
+		for _, value := range values {
+			expected := 0.
+			for _, action := range value.Actions {
+				result = math.Max(result, action.EV-value.EV)
+				expected += action.Probability * action.Regret
+			}
+			if math.Abs(expected) > 1e-6 {
+				t.Errorf("Regrets weighted by strategy should cancel out, got %v in %v", expected, value.InformationSet)
+			}
+		}
+		return result
+	}
+	if regret := maxRegret(uniform); regret < 0.5 {
+		t.Errorf("Uniformly random strategy should leave large regrets, got %v", regret)
+	}
+	if regret := maxRegret(ne); regret > 0.05 {
+		t.Errorf("Nash equilibrium approximation should leave almost no regret, got %v", regret)
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := WriteCounterfactualValues(buffer, root, ne); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(buffer.String(), "\n"); lines != 25 {
+		t.Errorf("Header and one record per action of 12 information sets expected, got %v lines", lines)
+	}
+}
+
+func TestKuhnPokerTreeParallelNashEquilibrium(t *testing.T) {
+
+	root := createRootForKuhnPokerTest(1000., 1000.)
+	ne := CreateComputingRoutine(root).ComputeNashEquilibriumViaTreeParallelCFR(2000, 4)
+
+	if exploitability := Exploitability(root, ne); exploitability < 0 || exploitability > 0.01 {
+		t.Errorf("Nash equilibrium approximation should be barely exploitable, got %v", exploitability)
+	}
+}
+
+func TestTreeParallelAndSampledIterationsCumulateOnTheSameScale(t *testing.T) {
+
+	root := createRootForKuhnPokerTest(1000., 1000.)
+	for _, parallel := range []bool{false, true} {
+		routine := CreateComputingRoutine(root)
+		if parallel {
+			routine.ComputeNashEquilibriumViaTreeParallelCFR(100, 4)
+		} else {
+			routine.ComputeNashEquilibriumViaCFR(100, 1)
+		}
+		// first decision of player A is reached with probability 1 whatever the deal is
+		firstDecisions := map[games.InformationSet]bool{}
+		for _, deal := range root.Actions() {
+			firstDecisions[root.Act(deal).InformationSet()] = true
+		}
+		sum := float32(0)
+		for infSet := range firstDecisions {
+			sum += routine.sigmaSum.sumValuesForInformationSet(infSet)
+		}
+		if math.Abs(float64(sum)-100) > 1e-3 {
+			t.Errorf("Strategy sums of first decisions should grow by 1 per iteration (parallel %v), got %v after 100", parallel, sum)
+		}
+	}
+}
+
+func TestTreeParallelCFRIsDeterministic(t *testing.T) {
+
+	defer func(maxRaises int) { rhodeisland.MaxRaises = maxRaises }(rhodeisland.MaxRaises)
+	rhodeisland.MaxRaises = 1
+	root := createRootForRhodeIslandPokerTest(1000., 1000.)
+	routine := CreateComputingRoutine(root)
+	routine.SetSeed(7)
+	first := routine.ComputeNashEquilibriumViaTreeParallelCFR(3, 1)
+	routine = CreateComputingRoutine(root)
+	routine.SetSeed(7)
+	second := routine.ComputeNashEquilibriumViaTreeParallelCFR(3, 8)
+
+	if !reflect.DeepEqual(first.Value, second.Value) {
+		t.Error("Strategy should not depend on the number of workers")
+	}
+}
+
+func TestEveryIterationIsRun(t *testing.T) {
+
+	root := createRootForKuhnPokerTest(1000., 1000.)
+	for _, parallel := range []bool{false, true} {
+		routine := CreateComputingRoutine(root)
+		if parallel {
+			routine.ComputeNashEquilibriumViaTreeParallelCFR(10, 4)
+		} else {
+			routine.ComputeNashEquilibriumViaCFR(10, 4)
+		}
+		// first decision of player A is reached once per sampled deal, every outcome of root counts with its probability
+		infSets := map[games.InformationSet]bool{}
+		for _, deal := range root.Actions() {
+			infSets[root.Act(deal).InformationSet()] = true
+		}
+		iterations := float32(0)
+		for infSet := range infSets {
+			for _, value := range routine.sigmaSum.Value[infSet] {
+				iterations += value
+			}
+		}
+		if math.Abs(float64(iterations-10)) > 1e-3 {
+			t.Errorf("10 iterations should be run, got %v (tree-parallel %v)", iterations, parallel)
+		}
+	}
+}
+
+func TestStrategyMapSerializationRoundTrip(t *testing.T) {
+
+	root := createRootForKuhnPokerTest(1000., 1000.)
+	ne := CreateComputingRoutine(root).ComputeNashEquilibriumViaCFR(1000, 1)
+	buffer := &bytes.Buffer{}
+	if err := WriteStrategyMap(buffer, ne); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ReadStrategyMap(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ne.Value, decoded.Value) {
+		t.Error("Strategy should not change after serialization round trip")
+	}
+}
+
+func TestDumpStrategyMapIsReadable(t *testing.T) {
+
+	root := createRootForKuhnPokerTest(1000., 1000.)
+	ne := CreateComputingRoutine(root).ComputeNashEquilibriumViaCFR(1000, 1)
+	buffer := &bytes.Buffer{}
+	if err := DumpStrategyMap(buffer, root, ne); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 12 {
+		t.Errorf("Kuhn Poker has 12 information sets, %v dumped", len(lines))
+	}
+	if !strings.Contains(buffer.String(), "♥K | - | Ch B: C ") {
+		t.Errorf("Information sets should be decoded, got:\n%v", buffer.String())
+	}
+}
+
 func TestRhodeISlandPokerNashEquilibrium(t *testing.T) {
 
 	rhodeisland.MaxRaises = 0
@@ -28,6 +205,120 @@ func TestRhodeISlandPokerNashEquilibrium(t *testing.T) {
 	routine.ComputeNashEquilibriumViaCFR(100, 8)
 }
 
+func TestKuhnPokerRegretPruning(t *testing.T) {
+
+	root := createRootForKuhnPokerTest(1000., 1000.)
+	routine := CreateComputingRoutine(root)
+	routine.SetRegretPruning(true)
+	ne := routine.ComputeNashEquilibriumViaCFR(50000, 1)
+
+	if exploitability := Exploitability(root, ne); exploitability < 0 || exploitability > 0.02 {
+		t.Errorf("Pruning should not prevent convergence, got exploitability %v", exploitability)
+	}
+}
+
+func TestRhodeIslandRegretPruningVisitsFewerStates(t *testing.T) {
+
+	defer func(maxRaises int) { rhodeisland.MaxRaises = maxRaises }(rhodeisland.MaxRaises)
+	rhodeisland.MaxRaises = 0
+	visited, exploitability := [2]int{}, [2]float32{}
+	for i, pruning := range []bool{false, true} {
+		root := countingState{rhodeisland.NewRoot(1000., cards.CreateLimitedDeck(cards.King, true)), &visited[i]}
+		routine := CreateComputingRoutine(root)
+		routine.SetRegretPruning(pruning)
+		routine.SetSeed(1)
+		exploitability[i] = Exploitability(root.GameState, routine.ComputeNashEquilibriumViaTreeParallelCFR(40, 1))
+	}
+
+	if visited[1] > visited[0]*3/4 {
+		t.Errorf("Pruning should skip at least a quarter of states, %v visited with pruning and %v without", visited[1], visited[0])
+	}
+	// pruning loses no update, only chance is sampled differently below skipped subtrees
+	if exploitability[1] > exploitability[0]*1.1 {
+		t.Errorf("Exploitability with pruning %v should stay within 10%% of %v without it", exploitability[1], exploitability[0])
+	}
+}
+
+func TestSuitIsomorphicRhodeIslandTraining(t *testing.T) {
+
+	defer func(maxRaises int) { rhodeisland.MaxRaises = maxRaises }(rhodeisland.MaxRaises)
+	rhodeisland.MaxRaises = 0
+	root := rhodeisland.NewRoot(1000., cards.CreateLimitedDeck(cards.King, true))
+	isomorphism := rhodeisland.SuitIsomorphism{}
+	abstract := CreateAbstractedComputingRoutine(root, isomorphism).ComputeNashEquilibriumViaCFR(3000, 1)
+
+	for infSet := range abstract.Value {
+		if isomorphism.Abstract(infSet) != infSet {
+			t.Fatal("Abstracted routine should only learn abstract information sets")
+		}
+	}
+	expanded := ExpandStrategy(abstract, isomorphism)
+	if len(expanded.Value) < 4*len(abstract.Value) {
+		t.Errorf("Every abstract information set stands for at least 4 raw ones, %v abstract and %v expanded", len(abstract.Value), len(expanded.Value))
+	}
+	if Exploitability(root, expanded) >= Exploitability(root, newStrategyMap()) {
+		t.Error("Expanded strategy should be less exploitable than uniformly random one")
+	}
+}
+
+func TestImperfectRecallRhodeIslandTraining(t *testing.T) {
+
+	defer func(maxRaises int) { rhodeisland.MaxRaises = maxRaises }(rhodeisland.MaxRaises)
+	rhodeisland.MaxRaises = 0
+	deck := cards.CreateLimitedDeck(cards.King, true)
+	root := rhodeisland.NewRoot(1000., deck)
+	abstraction, err := rhodeisland.CreateCardAbstraction(deck, [3]int{2, 3, 3}, rhodeisland.ExpectedHandStrength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abstraction.ImperfectRecall = true
+	strategy := CreateImperfectRecallComputingRoutine(root, abstraction).ComputeNashEquilibriumViaCFR(1000, 2)
+
+	for infSet := range strategy.Value {
+		if _, ok := infSet.(rhodeisland.BucketInformationSet); !ok {
+			t.Fatal("Imperfect recall routine should only learn abstract information sets")
+		}
+	}
+	uniform := ExploitabilityWithAbstraction(root, newStrategyMap(), abstraction)
+	if uniform != Exploitability(root, newStrategyMap()) {
+		t.Error("Uniformly random strategy is equally exploitable with and without abstraction")
+	}
+	valueA := BestResponseValueWithAbstraction(root, strategy, abstraction, acting.PlayerA)
+	valueB := BestResponseValueWithAbstraction(root, strategy, abstraction, acting.PlayerB)
+	if exploitability := (valueA + valueB) / 2; exploitability >= uniform {
+		t.Errorf("Strategy of abstracted game should be less exploitable than uniformly random one, got %v and %v", exploitability, uniform)
+	}
+	if utility := computeUtility(root, strategy, abstraction); utility > valueA+1e-4 || -utility > valueB+1e-4 {
+		t.Errorf("Best responses should gain at least utility of the strategy played against itself, got %v, %v and %v", utility, valueA, valueB)
+	}
+}
+
+func TestImportedMyersonCardGameNashEquilibrium(t *testing.T) {
+
+	root, err := efg.Import(strings.NewReader(`EFG 2 R "Myerson's card game" { "Player 1" "Player 2" } ""
+c "" 1 "" { "Red" 1/2 "Black" 1/2 } 0
+p "" 1 1 "" { "Raise" "Fold" } 0
+p "" 2 1 "" { "Meet" "Pass" } 0
+t "" 1 "" { 2, -2 }
+t "" 2 "" { 1, -1 }
+t "" 3 "" { 1, -1 }
+p "" 1 2 "" { "Raise" "Fold" } 0
+p "" 2 1 0
+t "" 4 "" { -2, 2 }
+t "" 2
+t "" 5 "" { -1, 1 }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	routine := CreateComputingRoutine(root)
+	ne := routine.ComputeNashEquilibriumViaCFR(20000, 1)
+	utility := computeUtility(root, ne, nil)
+
+	if utility < 0.32 || utility > 0.35 {
+		t.Errorf("Myerson's card game is worth 1/3 for player 1, got %v", utility)
+	}
+}
+
 func createRootForKuhnPokerTest(playerAStack float32, playerBStack float32) *kuhn.KuhnGameState {
 	playerA := &kuhn.Player{Id: acting.PlayerA, Actions: nil, Card: nil, Stack: playerAStack}
 	playerB := &kuhn.Player{Id: acting.PlayerB, Actions: nil, Card: nil, Stack: playerBStack}
@@ -39,3 +330,34 @@ func createRootForRhodeIslandPokerTest(playerAStack float32, playerBStack float3
 	playerB := &rhodeisland.Player{Id: acting.PlayerB, Actions: nil, Card: nil, Stack: playerBStack}
 	return rhodeisland.Root(playerA, playerB, cards.CreateLimitedDeck(cards.C10, true))
 }
+
+// countingState - game state counting states reached from it with Act
+type countingState struct {
+	games.GameState
+	count *int
+}
+
+func (state countingState) Act(action acting.Action) games.GameState {
+	*state.count++
+	return countingState{state.GameState.Act(action), state.count}
+}
+
+func benchmarkRhodeIslandTraining(b *testing.B, pruning bool) {
+	defer func(maxRaises int) { rhodeisland.MaxRaises = maxRaises }(rhodeisland.MaxRaises)
+	rhodeisland.MaxRaises = 3
+	root := createRootForRhodeIslandPokerTest(1000., 1000.)
+	routine := CreateComputingRoutine(root)
+	routine.SetRegretPruning(pruning)
+	// regrets have to settle before pruning pays off
+	routine.ComputeNashEquilibriumViaCFR(500, 1)
+	b.ResetTimer()
+	routine.ComputeNashEquilibriumViaCFR(b.N, 1)
+}
+
+func BenchmarkRhodeIslandTraining(b *testing.B) {
+	benchmarkRhodeIslandTraining(b, false)
+}
+
+func BenchmarkRhodeIslandTrainingWithRegretPruning(b *testing.B) {
+	benchmarkRhodeIslandTraining(b, true)
+}
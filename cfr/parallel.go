@@ -0,0 +1,161 @@
+package cfr
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+)
+
+// iterationDelta - regret and strategy sum increments of one task of a tree-parallel iteration
+type iterationDelta struct {
+	// weight - probability of the root chance outcome the task starts from. Sampled iterations (ComputeNashEquilibriumViaCFR)
+	// add increments of one root outcome drawn with that probability unweighted, so an iteration of either kind adds the same
+	// amount in expectation and tables of both are on the same scale.
+	weight    float32
+	rng       *rand.Rand
+	regrets   StrategyMap
+	sigmaSums StrategyMap
+}
+
+type parallelTask struct {
+	state games.GameState
+	delta *iterationDelta
+}
+
+func newIterationDelta(weight float32, seed int64) *iterationDelta {
+	return &iterationDelta{weight: weight, rng: rand.New(rand.NewSource(seed)), regrets: newStrategyMap(), sigmaSums: newStrategyMap()}
+}
+
+// SetSeed - seed of chance sampling in tree-parallel iterations, routine is seeded with 1 by default
+func (routine *ComputingRoutine) SetSeed(seed int64) {
+	routine.seeds = rand.New(rand.NewSource(seed))
+}
+
+// random - source of chance sampling, nil (math/rand global source) outside of tree-parallel iterations
+func (delta *iterationDelta) random() *rand.Rand {
+	if delta == nil {
+		return nil
+	}
+	return delta.rng
+}
+
+// ComputeNashEquilibriumViaTreeParallelCFR - runs exactly iterations of CFR with work split at the root chance node: every outcome
+// of the root (weighted by its probability) is traversed by one of numWorkers goroutines, chance is sampled below it. Workers
+// only read shared tables, their increments are merged in order of root outcomes once an iteration is over, so the strategy
+// does not depend on scheduling (see SetSeed). Games without chance node at the
+// root are traversed by a single worker.
+func (routine *ComputingRoutine) ComputeNashEquilibriumViaTreeParallelCFR(iterations int, numWorkers int) StrategyMap {
+
+	for i := 0; i < iterations; i++ {
+		tasks := routine.splitRoot()
+		indices := make(chan int)
+		group := &sync.WaitGroup{}
+		for worker := 0; worker < numWorkers || worker == 0; worker++ {
+			group.Add(1)
+			go func() {
+				for index := range indices {
+					routine.cfrUtilityRecursive(traversalState(tasks[index].state), 1, 1, tasks[index].delta)
+				}
+				group.Done()
+			}()
+		}
+		for index := range tasks {
+			indices <- index
+		}
+		close(indices)
+		group.Wait()
+		routine.merge(tasks)
+	}
+	return routine.computeNashEquilibriumBasedOnStrategySum()
+}
+
+// splitRoot - tasks of an iteration, one per possible outcome of root chance node
+func (routine *ComputingRoutine) splitRoot() []parallelTask {
+	if routine.seeds == nil {
+		routine.SetSeed(1)
+	}
+	root := routine.root
+	if root.IsTerminal() || root.CurrentActor().GetID() != acting.ChanceId {
+		return []parallelTask{{root, newIterationDelta(1, routine.seeds.Int63())}}
+	}
+	actions := root.Actions()
+	tasks := []parallelTask{}
+	for i, probability := range games.ChanceProbabilities(root, actions) {
+		if probability > 0 {
+			tasks = append(tasks, parallelTask{root.Act(actions[i]), newIterationDelta(probability, routine.seeds.Int63())})
+		}
+	}
+	return tasks
+}
+
+// merge - adds increments of tasks in their order, sigma of information sets with updated regrets is recomputed
+func (routine *ComputingRoutine) merge(tasks []parallelTask) {
+	updated := map[games.InformationSet]bool{}
+	for _, task := range tasks {
+		routine.add(task.delta.regrets, task.delta.sigmaSums, updated)
+	}
+	for infSet := range updated {
+		routine.updateSigma(infSet)
+	}
+}
+
+func (routine *ComputingRoutine) add(regrets StrategyMap, sigmaSums StrategyMap, updated map[games.InformationSet]bool) {
+	for infSet, actions := range regrets.Value {
+		routine.regretsSum.initIfZero(infSet)
+		for _, action := range sortedActions(actions) {
+			routine.regretsSum.Value[infSet][action] += actions[action]
+		}
+		updated[infSet] = true
+	}
+	for infSet, actions := range sigmaSums.Value {
+		routine.sigmaSum.initIfZero(infSet)
+		for _, action := range sortedActions(actions) {
+			routine.sigmaSum.Value[infSet][action] += actions[action]
+		}
+	}
+}
+
+// Snapshot - copy of the current strategy of the routine, the one the next iteration plays
+func (routine *ComputingRoutine) Snapshot() StrategyMap {
+	routine.sigma.mutex.Lock()
+	defer routine.sigma.mutex.Unlock()
+	snapshot := newStrategyMap()
+	for infSet, actions := range routine.sigma.Value {
+		snapshot.Value[infSet] = map[acting.ActionName]float32{}
+		for action, value := range actions {
+			snapshot.Value[infSet][action] = value
+		}
+	}
+	return snapshot
+}
+
+// Traverse - runs iterations of chance sampling CFR from the root of the routine against fixed strategy sigma (e.g. Snapshot
+// of a remote routine), returns increments of regrets and strategy sums to be passed to Apply. Tables of the routine are
+// left untouched, pruning is off.
+func (routine *ComputingRoutine) Traverse(sigma StrategyMap, iterations int, seed int64) (regrets StrategyMap, sigmaSums StrategyMap) {
+	traversal := *routine
+	traversal.sigma = sigma
+	traversal.pruning = false
+	delta := newIterationDelta(1, seed)
+	root := traversalState(traversal.root)
+	for i := 0; i < iterations; i++ {
+		traversal.cfrUtilityRecursive(root, 1, 1, delta)
+	}
+	return delta.regrets, delta.sigmaSums
+}
+
+// Apply - adds increments returned by Traverse to the tables of the routine, current strategy is updated
+func (routine *ComputingRoutine) Apply(regrets StrategyMap, sigmaSums StrategyMap) {
+	updated := map[games.InformationSet]bool{}
+	routine.add(regrets, sigmaSums, updated)
+	for infSet := range updated {
+		routine.updateSigma(infSet)
+	}
+}
+
+// AverageStrategy - average strategy of iterations run so far, approximation of Nash equilibrium
+func (routine *ComputingRoutine) AverageStrategy() StrategyMap {
+	return routine.computeNashEquilibriumBasedOnStrategySum()
+}
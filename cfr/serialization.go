@@ -0,0 +1,62 @@
+package cfr
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+)
+
+type strategyEntry struct {
+	InformationSet games.InformationSet
+	Actions        map[acting.ActionName]float32
+}
+
+// WriteStrategyMap - encodes strategy with encoding/gob, concrete information set types have to be registered with gob.Register
+func WriteStrategyMap(w io.Writer, strategy StrategyMap) error {
+	entries := make([]strategyEntry, 0, len(strategy.Value))
+	for infSet, actions := range strategy.Value {
+		entries = append(entries, strategyEntry{infSet, actions})
+	}
+	return gob.NewEncoder(w).Encode(entries)
+}
+
+// ReadStrategyMap - decodes strategy written by WriteStrategyMap
+func ReadStrategyMap(r io.Reader) (StrategyMap, error) {
+	entries := []strategyEntry{}
+	if err := gob.NewDecoder(r).Decode(&entries); err != nil {
+		return StrategyMap{}, err
+	}
+	strategy := newStrategyMap()
+	for _, entry := range entries {
+		strategy.Value[entry.InformationSet] = entry.Actions
+	}
+	return strategy, nil
+}
+
+// DumpStrategyMap - writes strategy in readable form, one information set per line (sorted), decoded by game when it is a games.InformationSetDescriber
+func DumpStrategyMap(w io.Writer, game games.GameState, strategy StrategyMap) error {
+	lines := make([]string, 0, len(strategy.Value))
+	for infSet, actions := range strategy.Value {
+		names := make([]acting.ActionName, 0, len(actions))
+		for name := range actions {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool { return names[i].String() < names[j].String() })
+		line := games.DescribeInformationSet(game, infSet) + ":"
+		for _, name := range names {
+			line += fmt.Sprintf(" %v %.3f", name, actions[name])
+		}
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,8 +1,33 @@
 package cfr
 
+import "github.com/int8/go-counterfactual-regret-minimization/acting"
+
 func maxFloat32(a float32, b float32) float32 {
 	if a > b {
 		return a
 	}
 	return b
 }
+
+// sortedActions - actions of the map in fixed order (insertion sort, information sets have a few actions only)
+func sortedActions(values map[acting.ActionName]float32) []acting.ActionName {
+	actions := make([]acting.ActionName, 0, len(values))
+	for action := range values {
+		i := len(actions)
+		actions = append(actions, action)
+		for ; i > 0 && actionLess(action, actions[i-1]); i-- {
+			actions[i] = actions[i-1]
+		}
+		actions[i] = action
+	}
+	return actions
+}
+
+func actionLess(action acting.ActionName, other acting.ActionName) bool {
+	for i := range action {
+		if action[i] != other[i] {
+			return other[i]
+		}
+	}
+	return false
+}
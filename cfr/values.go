@@ -0,0 +1,155 @@
+package cfr
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+)
+
+// ActionValue - values of an action of information set for the acting player
+type ActionValue struct {
+	Action      acting.ActionName
+	Probability float64
+	// Value - counterfactual value of the action (weighted by reach of chance and opponent)
+	Value float64
+	// EV - expected payoff of the action once information set is reached, Value divided by reach
+	EV float64
+	// Regret - counterfactual regret of not always playing the action, positive when action beats the strategy
+	Regret float64
+}
+
+// InformationSetValue - counterfactual value of information set under a strategy, along with values of its actions
+type InformationSetValue struct {
+	InformationSet games.InformationSet
+	Player         acting.ActorID
+	// Reach - probability of chance and opponent actions leading to the information set
+	Reach float64
+	// Value - counterfactual value of the information set
+	Value float64
+	// EV - expected payoff of the acting player once information set is reached, Value divided by reach
+	EV      float64
+	Actions []ActionValue
+}
+
+type valueAccumulator struct {
+	strategy StrategyMap
+	infSets  map[games.InformationSet]*InformationSetValue
+	order    []games.InformationSet
+}
+
+// CounterfactualValues - counterfactual values of all information sets reached when both players follow strategy
+// (uniform in information sets it does not know), in order of first visit
+func CounterfactualValues(root games.GameState, strategy StrategyMap) []InformationSetValue {
+	accumulator := &valueAccumulator{strategy: strategy, infSets: map[games.InformationSet]*InformationSetValue{}}
+	accumulator.utility(root, 1, 1, 1)
+	values := make([]InformationSetValue, 0, len(accumulator.order))
+	for _, infSet := range accumulator.order {
+		value := *accumulator.infSets[infSet]
+		if value.Reach > 0 {
+			value.EV = value.Value / value.Reach
+		}
+		for i := range value.Actions {
+			value.Actions[i].Regret = value.Actions[i].Value - value.Value
+			if value.Reach > 0 {
+				value.Actions[i].EV = value.Actions[i].Value / value.Reach
+			}
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// utility - expected payoff of player A, counterfactual values are cumulated along the way
+func (accumulator *valueAccumulator) utility(state games.GameState, reachA float64, reachB float64, reachChance float64) float64 {
+	if state.IsTerminal() {
+		return float64(state.Evaluate())
+	}
+	actions := state.Actions()
+	if state.CurrentActor().GetID() == acting.ChanceId {
+		value := 0.
+		for i, probability := range games.ChanceProbabilities(state, actions) {
+			if probability > 0 {
+				value += float64(probability) * accumulator.utility(state.Act(actions[i]), reachA, reachB, reachChance*float64(probability))
+			}
+		}
+		return value
+	}
+
+	player := state.CurrentActor().GetID()
+	probabilities := StrategyProbabilities(accumulator.strategy, state.InformationSet(), actions)
+	childUtilities := make([]float64, len(actions))
+	value := 0.
+	for i, action := range actions {
+		if player == acting.PlayerA {
+			childUtilities[i] = accumulator.utility(state.Act(action), reachA*probabilities[i], reachB, reachChance)
+		} else {
+			childUtilities[i] = accumulator.utility(state.Act(action), reachA, reachB*probabilities[i], reachChance)
+		}
+		value += probabilities[i] * childUtilities[i]
+	}
+
+	cfrReach := reachChance * reachB
+	if player == acting.PlayerB {
+		cfrReach = reachChance * reachA
+	}
+	infSetValue := accumulator.informationSetValue(state, actions, probabilities)
+	infSetValue.Reach += cfrReach
+	infSetValue.Value += cfrReach * float64(player) * value
+	for i := range actions {
+		infSetValue.Actions[i].Value += cfrReach * float64(player) * childUtilities[i]
+	}
+	return value
+}
+
+func (accumulator *valueAccumulator) informationSetValue(state games.GameState, actions []acting.Action, probabilities []float64) *InformationSetValue {
+	infSet := state.InformationSet()
+	if value, ok := accumulator.infSets[infSet]; ok {
+		return value
+	}
+	value := &InformationSetValue{InformationSet: infSet, Player: state.CurrentActor().GetID(), Actions: make([]ActionValue, len(actions))}
+	for i, action := range actions {
+		value.Actions[i] = ActionValue{Action: action.Name(), Probability: probabilities[i]}
+	}
+	accumulator.infSets[infSet] = value
+	accumulator.order = append(accumulator.order, infSet)
+	return value
+}
+
+// WriteCounterfactualValues - writes values as CSV, one record per action (sorted by information set), information sets are
+// decoded by game when it is a games.InformationSetDescriber
+func WriteCounterfactualValues(w io.Writer, game games.GameState, values []InformationSetValue) error {
+	sorted := append([]InformationSetValue{}, values...)
+	descriptions := map[games.InformationSet]string{}
+	for _, value := range sorted {
+		descriptions[value.InformationSet] = games.DescribeInformationSet(game, value.InformationSet)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return descriptions[sorted[i].InformationSet] < descriptions[sorted[j].InformationSet]
+	})
+
+	writer := csv.NewWriter(w)
+	header := []string{"information_set", "player", "reach", "value", "ev", "action", "probability", "action_value", "action_ev", "regret"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, value := range sorted {
+		for _, action := range value.Actions {
+			record := []string{descriptions[value.InformationSet], acting.PlayerName(value.Player), formatFloat(value.Reach), formatFloat(value.Value),
+				formatFloat(value.EV), action.Action.String(), formatFloat(action.Probability), formatFloat(action.Value),
+				formatFloat(action.EV), formatFloat(action.Regret)}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatFloat(value float64) string {
+	return fmt.Sprintf("%.6f", value)
+}
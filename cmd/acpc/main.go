@@ -0,0 +1,100 @@
+// Command acpc plays trained strategies over the ACPC dealer protocol
+//
+//	acpc dealer --game rhodeisland --min-card 10 --max-raises 1 --addr localhost:18791 --hands 1000
+//	acpc client --strategy strategy.bin --addr localhost:18791 --translation randomized
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"time"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acpc"
+	"github.com/int8/go-counterfactual-regret-minimization/games/bundled"
+	"github.com/int8/go-counterfactual-regret-minimization/translation"
+)
+
+const usage = "usage: acpc dealer|client [flags]"
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "dealer":
+		return dealer(args[1:])
+	case "client":
+		return client(args[1:])
+	}
+	return fmt.Errorf("unknown command %q\n%v", args[0], usage)
+}
+
+func dealer(args []string) error {
+	flags := flag.NewFlagSet("dealer", flag.ContinueOnError)
+	config := &bundled.Config{}
+	flags.StringVar(&config.Game, "game", bundled.Kuhn, "game to play: kuhn or rhodeisland")
+	flags.IntVar(&config.MinCard, "min-card", 10, "lowest card of rhodeisland deck (2-14), 2 for full deck")
+	flags.IntVar(&config.MaxRaises, "max-raises", 1, "maximal number of raises per rhodeisland betting round")
+	addr := flags.String("addr", "localhost:18791", "address to wait for two players on")
+	hands := flags.Int("hands", 1000, "number of hands")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "seed of dealt cards")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	root, err := config.Root()
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	fmt.Printf("dealing %v hands of %v on %v\n", *hands, config, listener.Addr())
+	d := &acpc.Dealer{Root: root, Hands: *hands, Rng: rand.New(rand.NewSource(*seed))}
+	winnings, err := d.Serve(listener)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("first player won %v, second player won %v\n", winnings[0], winnings[1])
+	return nil
+}
+
+func client(args []string) error {
+	flags := flag.NewFlagSet("client", flag.ContinueOnError)
+	strategyFile := flags.String("strategy", "strategy.bin", "strategy file written by cfr train")
+	addr := flags.String("addr", "localhost:18791", "dealer address")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "seed of bot decisions")
+	rounding := flags.String("translation", "", "translate sized raises of no-limit dealers to bets of the game: randomized or deterministic, sizes are ignored when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	roundings := map[string]translation.Rounding{"randomized": translation.Randomized, "deterministic": translation.Deterministic}
+	if _, ok := roundings[*rounding]; !ok && *rounding != "" {
+		return fmt.Errorf("unknown translation %q", *rounding)
+	}
+	config, strategy, err := bundled.LoadFile(*strategyFile)
+	if err != nil {
+		return err
+	}
+	root, err := config.Root()
+	if err != nil {
+		return err
+	}
+	bot := acpc.NewClient(root, strategy, *seed)
+	if *rounding != "" {
+		bot.Translator = &translation.Translator{Rounding: roundings[*rounding]}
+	}
+	return bot.Dial(*addr)
+}
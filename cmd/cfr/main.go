@@ -0,0 +1,435 @@
+// Command cfr trains and inspects strategies of the games bundled with the repository
+//
+//	cfr train --game rhodeisland --min-card 10 --max-raises 1 --algo cfr --iterations 100000 --threads 8 --out strategy.bin
+//	cfr coordinate --game rhodeisland --min-card 2 --iterations 1000000 --batch 1000 --listen :7070 --out strategy.bin
+//	cfr work --game rhodeisland --min-card 2 --coordinator host:7070
+//	cfr report --strategy strategy.bin --format html --ranges "rc/r Kh" --out report.html
+//	cfr diff --values first.bin second.bin
+//	cfr values --strategy strategy.bin --out values.csv
+//	cfr buckets --min-card 10 --buckets 3,5,5 --feature ehs2 --out buckets.bin
+//	cfr train --game rhodeisland --min-card 10 --abstraction buckets.bin --out strategy.bin
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/int8/go-counterfactual-regret-minimization/belief"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/compare"
+	"github.com/int8/go-counterfactual-regret-minimization/distributed"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/bundled"
+	"github.com/int8/go-counterfactual-regret-minimization/games/rhodeisland"
+	"github.com/int8/go-counterfactual-regret-minimization/report"
+)
+
+const usage = `usage: cfr <command> [flags]
+
+commands:
+  train       compute (approximate) Nash equilibrium of a bundled game and save it
+  coordinate  hand out iterations of training to workers over TCP and save the merged strategy
+  work        run iterations handed out by a coordinator
+  report      write action frequencies of a saved strategy as text, CSV or HTML
+  diff        compare two saved strategies of the same game
+  values      export counterfactual values, action EVs and regrets of a saved strategy as CSV
+  buckets     cluster rhodeisland hands by hand strength and save the card abstraction
+
+run "cfr <command> --help" for command flags
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "train":
+		return train(args[1:], stdout)
+	case "coordinate":
+		return coordinate(args[1:], stdout)
+	case "work":
+		return work(args[1:], stdout)
+	case "report":
+		return writeReport(args[1:], stdout)
+	case "diff":
+		return diff(args[1:], stdout)
+	case "values":
+		return values(args[1:], stdout)
+	case "buckets":
+		return buckets(args[1:], stdout)
+	}
+	return fmt.Errorf("unknown command %q\n%v", args[0], usage)
+}
+
+func gameFlags(flags *flag.FlagSet) *bundled.Config {
+	config := &bundled.Config{}
+	flags.StringVar(&config.Game, "game", bundled.Kuhn, "game to play: kuhn or rhodeisland")
+	flags.IntVar(&config.MinCard, "min-card", 10, "lowest card of rhodeisland deck (2-14), 2 for full deck")
+	flags.IntVar(&config.MaxRaises, "max-raises", 1, "maximal number of raises per rhodeisland betting round")
+	flags.BoolVar(&config.BitmaskDeck, "bitmask-deck", false, "deal from uint64 bitmask deck instead of map based one")
+	return config
+}
+
+func train(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("train", flag.ContinueOnError)
+	config := gameFlags(flags)
+	algo := flags.String("algo", "cfr", "training algorithm: cfr (chance sampling)")
+	iterations := flags.Int("iterations", 10000, "number of iterations")
+	threads := flags.Int("threads", 1, "number of goroutines")
+	parallel := flags.String("parallel", "iteration", "parallel mode: iteration (threads run whole iterations on shared tables) or tree (iterations split at the root chance node, deterministic)")
+	progress := flags.Int("progress", 0, "iterations between progress reports, tenth of all iterations when 0")
+	exploitability := flags.Bool("exploitability", false, "report exploitability (always on for kuhn, slow for larger games)")
+	suitIsomorphism := flags.Bool("suit-isomorphism", false, "learn suit isomorphic rhodeisland information sets together, saved strategy is expanded")
+	abstractionFile := flags.String("abstraction", "", "card abstraction file written by buckets to learn rhodeisland hands of a bucket together, saved strategy is expanded")
+	prune := flags.Bool("prune", false, "skip subtrees both players reach with zero probability (regret-based pruning, no update is lost)")
+	out := flags.String("out", "strategy.bin", "strategy file to write")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *algo != "cfr" {
+		return fmt.Errorf("unknown algorithm %q", *algo)
+	}
+	if *iterations < 1 || *threads < 1 {
+		return errors.New("iterations and threads have to be positive")
+	}
+	if *parallel != "iteration" && *parallel != "tree" {
+		return fmt.Errorf("unknown parallel mode %q", *parallel)
+	}
+	root, err := config.Root()
+	if err != nil {
+		return err
+	}
+	*exploitability = *exploitability || config.Game == bundled.Kuhn
+	if *suitIsomorphism && config.Game != bundled.RhodeIsland {
+		return errors.New("suit isomorphism is available for rhodeisland only")
+	}
+	var abstraction *rhodeisland.CardAbstraction
+	if *abstractionFile != "" {
+		if config.Game != bundled.RhodeIsland || *suitIsomorphism {
+			return errors.New("card abstraction is available for rhodeisland without suit isomorphism only")
+		}
+		if abstraction, err = loadCardAbstraction(*abstractionFile, config.Deck()); err != nil {
+			return err
+		}
+	}
+
+	chunk := *progress
+	if chunk <= 0 {
+		chunk = *iterations / 10
+	}
+	if chunk <= 0 {
+		chunk = 1
+	}
+
+	fmt.Fprintf(stdout, "training %v with %v for %v iterations on %v threads\n", config, *algo, *iterations, *threads)
+	routine := cfr.CreateComputingRoutine(root)
+	if *suitIsomorphism {
+		routine = cfr.CreateAbstractedComputingRoutine(root, rhodeisland.SuitIsomorphism{})
+	}
+	if abstraction != nil && abstraction.ImperfectRecall {
+		routine = cfr.CreateImperfectRecallComputingRoutine(root, abstraction)
+	} else if abstraction != nil {
+		routine = cfr.CreateAbstractedComputingRoutine(root, abstraction)
+	}
+	routine.SetRegretPruning(*prune)
+	start := time.Now()
+	var strategy cfr.StrategyMap
+	for done := 0; done < *iterations; {
+		batch := chunk
+		if done+batch > *iterations {
+			batch = *iterations - done
+		}
+		if *parallel == "tree" {
+			strategy = routine.ComputeNashEquilibriumViaTreeParallelCFR(batch, *threads)
+		} else {
+			strategy = routine.ComputeNashEquilibriumViaCFR(batch, *threads)
+		}
+		done += batch
+		fmt.Fprintf(stdout, "iteration %v, %v information sets, %v elapsed", done, len(strategy.Value), time.Since(start).Round(time.Millisecond))
+		if *suitIsomorphism {
+			strategy = cfr.ExpandStrategy(strategy, rhodeisland.SuitIsomorphism{})
+		}
+		if *exploitability && abstraction != nil {
+			fmt.Fprintf(stdout, ", exploitability %.6f", cfr.ExploitabilityWithAbstraction(root, strategy, abstraction))
+		} else if *exploitability {
+			fmt.Fprintf(stdout, ", exploitability %.6f", cfr.Exploitability(root, strategy))
+		}
+		fmt.Fprintln(stdout)
+	}
+	if abstraction != nil {
+		strategy = cfr.ExpandAbstractStrategy(root, strategy, abstraction)
+	}
+
+	if err := bundled.SaveFile(*out, *config, strategy); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "strategy saved to %v\n", *out)
+	return nil
+}
+
+// loadCardAbstraction - card abstraction of abstraction file bound to deck
+func loadCardAbstraction(path string, deck cards.Deck) (*rhodeisland.CardAbstraction, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	abstraction, err := rhodeisland.LoadCardAbstraction(file)
+	if err != nil {
+		return nil, err
+	}
+	if err := abstraction.Bind(deck); err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+	return abstraction, nil
+}
+
+func coordinate(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("coordinate", flag.ContinueOnError)
+	config := gameFlags(flags)
+	iterations := flags.Int("iterations", 10000, "number of iterations")
+	batch := flags.Int("batch", 100, "iterations handed out to a worker at once")
+	listen := flags.String("listen", ":7070", "address to listen for workers on")
+	seed := flags.Int64("seed", 1, "seed of chance sampling of workers")
+	timeout := flags.Duration("timeout", 0, "batch not returned in time is handed out to another worker, 0 waits while its worker is connected")
+	out := flags.String("out", "strategy.bin", "strategy file to write")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	root, err := config.Root()
+	if err != nil {
+		return err
+	}
+	coordinator, err := distributed.NewCoordinator(config.String(), cfr.CreateComputingRoutine(root), *iterations, *batch, *seed)
+	if err != nil {
+		return err
+	}
+	coordinator.Timeout = *timeout
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return err
+	}
+	chunk := *iterations / 10
+	if chunk <= 0 {
+		chunk = 1
+	}
+	reported := 0
+	start := time.Now()
+	coordinator.Progress = func(merged int) {
+		if merged-reported >= chunk || merged == *iterations {
+			reported = merged
+			fmt.Fprintf(stdout, "iteration %v merged, %v elapsed\n", merged, time.Since(start).Round(time.Millisecond))
+		}
+	}
+	fmt.Fprintf(stdout, "training %v for %v iterations, workers connect to %v\n", config, *iterations, listener.Addr())
+	strategy, err := coordinator.Serve(listener)
+	if err != nil {
+		return err
+	}
+
+	if err := bundled.SaveFile(*out, *config, strategy); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "strategy saved to %v\n", *out)
+	return nil
+}
+
+func work(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("work", flag.ContinueOnError)
+	config := gameFlags(flags)
+	address := flags.String("coordinator", "localhost:7070", "address of the coordinator")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	root, err := config.Root()
+	if err != nil {
+		return err
+	}
+	done, err := distributed.Work(*address, config.String(), cfr.CreateComputingRoutine(root))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%v iterations run\n", done)
+	return nil
+}
+
+func writeReport(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("report", flag.ContinueOnError)
+	strategyFile := flags.String("strategy", "strategy.bin", "strategy file written by train")
+	format := flags.String("format", "text", "report format: text, csv or html")
+	out := flags.String("out", "", "report file to write, standard output when empty")
+	ranges := flags.String("ranges", "", "public states to show ranges of both players in (text and html only), separated with ';', e.g. \"rc/r Kh;cc/\"")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "csv" && *format != "html" {
+		return fmt.Errorf("unknown report format %q", *format)
+	}
+	publicStates := []belief.PublicState{}
+	if *ranges != "" {
+		for _, text := range strings.Split(*ranges, ";") {
+			public, err := belief.ParsePublicState(text)
+			if err != nil {
+				return err
+			}
+			publicStates = append(publicStates, public)
+		}
+	}
+
+	config, strategy, err := bundled.LoadFile(*strategyFile)
+	if err != nil {
+		return err
+	}
+	root, err := config.Root()
+	if err != nil {
+		return err
+	}
+
+	strategyReport := report.New(root, strategy)
+	if err := strategyReport.AddRanges(root.(games.PokerGameState), strategy, publicStates...); err != nil {
+		return err
+	}
+	return writeOutput(*out, stdout, func(w io.Writer) error {
+		switch *format {
+		case "csv":
+			return strategyReport.WriteCSV(w)
+		case "html":
+			return strategyReport.WriteHTML(w, fmt.Sprintf("Strategy of %v", config))
+		}
+		return strategyReport.WriteText(w)
+	})
+}
+
+// writeOutput - calls write with stdout or with created out file when out is set, error of closing the file is returned too
+func writeOutput(out string, stdout io.Writer, write func(w io.Writer) error) error {
+	if out == "" {
+		return write(stdout)
+	}
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := write(file); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func diff(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	top := flags.Int("top", 10, "number of information sets with largest differences to list")
+	values := flags.Bool("values", false, "compute exploitability and head-to-head value (always on for kuhn, slow for larger games)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return errors.New("diff needs two strategy files")
+	}
+
+	config, first, err := bundled.LoadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	secondConfig, second, err := bundled.LoadFile(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	if config != secondConfig {
+		return fmt.Errorf("strategies are computed for different games: %v and %v", config, secondConfig)
+	}
+	root, err := config.Root()
+	if err != nil {
+		return err
+	}
+
+	compare.Strategies(root, first, second).Write(stdout, *top)
+	if *values || config.Game == bundled.Kuhn {
+		compare.StrategyValues(root, first, second).Write(stdout)
+	}
+	return nil
+}
+
+func values(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("values", flag.ContinueOnError)
+	strategyFile := flags.String("strategy", "strategy.bin", "strategy file written by train")
+	out := flags.String("out", "", "CSV file to write, standard output when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	config, strategy, err := bundled.LoadFile(*strategyFile)
+	if err != nil {
+		return err
+	}
+	root, err := config.Root()
+	if err != nil {
+		return err
+	}
+	return writeOutput(*out, stdout, func(w io.Writer) error {
+		return cfr.WriteCounterfactualValues(w, root, cfr.CounterfactualValues(root, strategy))
+	})
+}
+
+func buckets(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("buckets", flag.ContinueOnError)
+	config := gameFlags(flags)
+	counts := flags.String("buckets", "3,5,5", "number of preflop, flop and turn buckets")
+	feature := flags.String("feature", "ehs", "clustered hand strength: ehs (expected hand strength) or ehs2 (its square, favours drawing hands)")
+	out := flags.String("out", "buckets.bin", "card abstraction file to write")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if config.Game != bundled.RhodeIsland {
+		return errors.New("card abstraction is available for rhodeisland only")
+	}
+	features := map[string]rhodeisland.Feature{"ehs": rhodeisland.ExpectedHandStrength, "ehs2": rhodeisland.ExpectedHandStrengthSquared}
+	if _, ok := features[*feature]; !ok {
+		return fmt.Errorf("unknown hand strength feature %q", *feature)
+	}
+	bucketCounts := [3]int{}
+	fields := strings.Split(*counts, ",")
+	if len(fields) != len(bucketCounts) {
+		return fmt.Errorf("three numbers of buckets expected, got %q", *counts)
+	}
+	for i, field := range fields {
+		count, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return fmt.Errorf("invalid number of buckets %q", field)
+		}
+		bucketCounts[i] = count
+	}
+
+	abstraction, err := rhodeisland.CreateCardAbstraction(config.Deck(), bucketCounts, features[*feature])
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := abstraction.Save(file); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%v hands of %v clustered into %v buckets, saved to %v\n", len(abstraction.Assignments), config, bucketCounts, *out)
+	return file.Close()
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/int8/go-counterfactual-regret-minimization/games/bundled"
+	"github.com/int8/go-counterfactual-regret-minimization/games/rhodeisland"
+)
+
+func TestTrainKuhnWritesStrategyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "kuhn.bin")
+
+	stdout := &bytes.Buffer{}
+	if err := run([]string{"train", "--game", "kuhn", "--iterations", "2005", "--threads", "4", "--out", out}, stdout); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(stdout.String(), "iteration 2005,") {
+		t.Errorf("Progress should reach all 2005 iterations, got:\n%v", stdout.String())
+	}
+	if strings.Count(stdout.String(), "exploitability") != 11 {
+		t.Errorf("Every progress line should report exploitability of kuhn, got:\n%v", stdout.String())
+	}
+
+	config, strategy, err := bundled.LoadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Game != bundled.Kuhn {
+		t.Errorf("Strategy file should keep the game, got %v", config.Game)
+	}
+	if len(strategy.Value) != 12 {
+		t.Errorf("Kuhn strategy should have 12 information sets, got %v", len(strategy.Value))
+	}
+
+	stdout.Reset()
+	if err := run([]string{"train", "--game", "kuhn", "--iterations", "25", "--threads", "4", "--parallel", "tree", "--out", out}, stdout); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "iteration 25,") {
+		t.Errorf("Tree-parallel training should run all 25 iterations, got:\n%v", stdout.String())
+	}
+}
+
+func TestDistributedTrainingOverLoopback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "kuhn.bin")
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	coordinated := make(chan error)
+	stdout := &bytes.Buffer{}
+	go func() {
+		coordinated <- run([]string{"coordinate", "--game", "kuhn", "--iterations", "1000", "--batch", "10", "--listen", address, "--out", out}, stdout)
+	}()
+	workers := make(chan error)
+	for i := 0; i < 2; i++ {
+		go func() {
+			// coordinator may not be listening yet
+			var err error
+			for attempt := 0; attempt < 50; attempt++ {
+				if err = run([]string{"work", "--game", "kuhn", "--coordinator", address}, ioutil.Discard); err == nil {
+					break
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+			workers <- err
+		}()
+	}
+	// worker connecting once all iterations are done finds coordinator gone
+	first, second := <-workers, <-workers
+	if first != nil && second != nil {
+		t.Fatal(first)
+	}
+	if err := <-coordinated; err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(stdout.String(), "iteration 1000 merged") {
+		t.Errorf("Coordinator should merge all iterations, got:\n%v", stdout.String())
+	}
+	if _, strategy, err := bundled.LoadFile(out); err != nil || len(strategy.Value) != 12 {
+		t.Errorf("Merged Kuhn strategy should be saved, got %v", err)
+	}
+}
+
+func TestReportOfTrainedStrategy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "kuhn.bin")
+	if err := run([]string{"train", "--game", "kuhn", "--iterations", "100", "--out", out}, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := &bytes.Buffer{}
+	if err := run([]string{"report", "--strategy", out, "--format", "csv"}, stdout); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(stdout.String(), "\n") != 13 {
+		t.Errorf("CSV report should have a line per information set, got:\n%v", stdout.String())
+	}
+
+	page := filepath.Join(dir, "report.html")
+	if err := run([]string{"report", "--strategy", out, "--format", "html", "--out", page}, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := ioutil.ReadFile(page); err != nil || !strings.Contains(string(content), "Strategy of kuhn") {
+		t.Errorf("HTML report should be written to file, got %v", err)
+	}
+	stdout.Reset()
+	if err := run([]string{"report", "--strategy", out, "--ranges", "r;cr"}, stdout); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "public state r (") || !strings.Contains(stdout.String(), "public state cr (") {
+		t.Errorf("Text report should show ranges of public states, got:\n%v", stdout.String())
+	}
+	if err := run([]string{"report", "--strategy", out, "--ranges", "x"}, ioutil.Discard); err == nil {
+		t.Error("Invalid public state should be rejected")
+	}
+	if err := run([]string{"report", "--strategy", out, "--format", "pdf"}, ioutil.Discard); err == nil {
+		t.Error("Unknown report format should be rejected")
+	}
+}
+
+func TestValuesOfTrainedStrategy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "kuhn.bin")
+	if err := run([]string{"train", "--game", "kuhn", "--iterations", "100", "--out", out}, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := &bytes.Buffer{}
+	if err := run([]string{"values", "--strategy", out}, stdout); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stdout.String(), "information_set,player,reach,value,ev,action,") || strings.Count(stdout.String(), "\n") != 25 {
+		t.Errorf("Values should have a line per action of every information set, got:\n%v", stdout.String())
+	}
+	if err := run([]string{"values", "--strategy", filepath.Join(dir, "missing.bin")}, ioutil.Discard); err == nil {
+		t.Error("Missing strategy file should be reported")
+	}
+}
+
+func TestDiffOfStrategies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	short, long, other := filepath.Join(dir, "short.bin"), filepath.Join(dir, "long.bin"), filepath.Join(dir, "other.bin")
+	for _, args := range [][]string{
+		{"train", "--game", "kuhn", "--iterations", "10", "--out", short},
+		{"train", "--game", "kuhn", "--iterations", "5000", "--out", long},
+		{"train", "--game", "rhodeisland", "--min-card", "13", "--iterations", "10", "--out", other},
+	} {
+		if err := run(args, ioutil.Discard); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdout := &bytes.Buffer{}
+	if err := run([]string{"diff", "--top", "2", short, long}, stdout); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "Preflop: ") || !strings.Contains(stdout.String(), "first against second") {
+		t.Errorf("Diff should report distances per round and values of kuhn strategies, got:\n%v", stdout.String())
+	}
+	if err := run([]string{"diff", short, other}, ioutil.Discard); err == nil {
+		t.Error("Strategies of different games should not be compared")
+	}
+	if err := run([]string{"diff", short}, ioutil.Discard); err == nil {
+		t.Error("Diff needs two strategies")
+	}
+}
+
+func TestTrainWithSuitIsomorphism(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "rhodeisland.bin")
+	if err := run([]string{"train", "--game", "rhodeisland", "--min-card", "13", "--max-raises", "0", "--iterations", "200", "--suit-isomorphism", "--out", out}, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	_, strategy, err := bundled.LoadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for infSet := range strategy.Value {
+		for _, raw := range (rhodeisland.SuitIsomorphism{}).Expand(infSet) {
+			if _, ok := strategy.Value[raw]; !ok {
+				t.Fatal("Saved strategy should be expanded to all suit isomorphic information sets")
+			}
+		}
+	}
+	if err := run([]string{"train", "--game", "kuhn", "--suit-isomorphism"}, ioutil.Discard); err == nil {
+		t.Error("Suit isomorphism should be rejected for kuhn")
+	}
+}
+
+func TestBucketsWritesCardAbstraction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "buckets.bin")
+	if err := run([]string{"buckets", "--game", "rhodeisland", "--min-card", "12", "--buckets", "2,3,3", "--feature", "ehs2", "--out", out}, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	abstraction, err := rhodeisland.LoadCardAbstraction(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if abstraction.Buckets != [3]int{2, 3, 3} || abstraction.Feature != rhodeisland.ExpectedHandStrengthSquared {
+		t.Errorf("Saved abstraction should keep its settings, got %v %v", abstraction.Buckets, abstraction.Feature)
+	}
+
+	smallBuckets, strategyFile := filepath.Join(dir, "small.bin"), filepath.Join(dir, "strategy.bin")
+	if err := run([]string{"buckets", "--game", "rhodeisland", "--min-card", "13", "--buckets", "2,2,2", "--out", smallBuckets}, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if err := run([]string{"train", "--game", "rhodeisland", "--min-card", "13", "--abstraction", smallBuckets, "--iterations", "200", "--out", strategyFile}, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	_, strategy, err := bundled.LoadFile(strategyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for infSet := range strategy.Value {
+		if _, raw := infSet.([rhodeisland.InformationSetSizeBytes]byte); !raw {
+			t.Fatalf("Strategy trained with card abstraction should be expanded to information sets of the game, got %v", infSet)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"buckets", "--game", "kuhn"},
+		{"buckets", "--game", "rhodeisland", "--buckets", "3,5"},
+		{"buckets", "--game", "rhodeisland", "--feature", "potential"},
+		{"train", "--game", "rhodeisland", "--min-card", "11", "--abstraction", out, "--out", strategyFile},
+		{"train", "--game", "rhodeisland", "--min-card", "13", "--abstraction", out, "--out", strategyFile},
+		{"train", "--game", "kuhn", "--abstraction", out, "--out", strategyFile},
+	} {
+		if err := run(args, ioutil.Discard); err == nil {
+			t.Errorf("%v should fail", args)
+		}
+	}
+}
+
+func TestTrainRejectsUnknownSettings(t *testing.T) {
+	for _, args := range [][]string{
+		{"train", "--game", "chess"},
+		{"train", "--algo", "magic"},
+		{"train", "--iterations", "0"},
+		{"train", "--parallel", "gpu"},
+		{"evaluate"},
+		{},
+	} {
+		if err := run(args, ioutil.Discard); err == nil {
+			t.Errorf("%v should fail", args)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Command play lets a human play kuhn or rhodeisland hands against a trained strategy
+//
+//	play --strategy strategy.bin
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/bundled"
+	"github.com/int8/go-counterfactual-regret-minimization/policy"
+)
+
+// errQuit - human asked to end the session
+var errQuit = errors.New("quit")
+
+type session struct {
+	root     games.GameState
+	bot      policy.Policy
+	input    *bufio.Scanner
+	output   io.Writer
+	rng      *rand.Rand
+	bankroll float32
+}
+
+func main() {
+	strategyFile := flag.String("strategy", "strategy.bin", "strategy file written by cfr train")
+	hands := flag.Int("hands", 0, "number of hands to play, unlimited when 0")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed of cards and bot decisions")
+	flag.Parse()
+
+	config, strategy, err := bundled.LoadFile(*strategyFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	root, err := config.Root()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("playing %v, type check, bet, call, raise or fold (or their first letters, ch for check), quit to leave\n", config)
+	s := newSession(root, policy.NewStrategyPolicy(strategy, nil), os.Stdin, os.Stdout, *seed)
+	s.play(*hands)
+}
+
+func newSession(root games.GameState, bot policy.Policy, input io.Reader, output io.Writer, seed int64) *session {
+	return &session{root: root, bot: bot, input: bufio.NewScanner(input), output: output, rng: rand.New(rand.NewSource(seed))}
+}
+
+// play - plays hands until the limit (unlimited when 0), human changes seats every hand
+func (s *session) play(hands int) {
+	for hand := 0; hands == 0 || hand < hands; hand++ {
+		human := acting.PlayerA
+		if hand%2 == 1 {
+			human = acting.PlayerB
+		}
+		fmt.Fprintf(s.output, "\nhand #%v, you are player %v\n", hand+1, seatName(human))
+		winnings, err := s.playHand(human)
+		if err != nil {
+			break
+		}
+		s.bankroll += winnings
+		fmt.Fprintf(s.output, "you %v %v, session bankroll %v\n", resultVerb(winnings), abs(winnings), s.bankroll)
+	}
+	fmt.Fprintf(s.output, "\nsession over, bankroll %v\n", s.bankroll)
+}
+
+func (s *session) playHand(human acting.ActorID) (float32, error) {
+	state := s.root
+	var action acting.Action
+	for !state.IsTerminal() {
+		var err error
+		switch state.CurrentActor().GetID() {
+		case acting.ChanceId:
+			action = games.SampleChance(state, s.rng)
+		case human:
+			s.show(state, human)
+			if action, err = s.ask(state); err != nil {
+				return 0, err
+			}
+		default:
+			action = s.bot.Sample(state, s.rng)
+			fmt.Fprintf(s.output, "bot: %v\n", action.Name().Word())
+		}
+		state = state.Act(action)
+	}
+
+	if poker, ok := state.(games.PokerGameState); ok && action.Name() != acting.Fold {
+		fmt.Fprintf(s.output, "showdown: your %v against bot's %v, board %v\n",
+			poker.PrivateCard(human), poker.PrivateCard(-human), boardString(poker))
+	}
+	return float32(human) * state.Evaluate(), nil
+}
+
+func (s *session) show(state games.GameState, human acting.ActorID) {
+	if poker, ok := state.(games.PokerGameState); ok {
+		fmt.Fprintf(s.output, "%v | your card %v | board %v | pot %v\n",
+			poker.Round(), poker.PrivateCard(human), boardString(poker), poker.Table().Pot)
+	}
+	names := []string{}
+	for _, action := range state.Actions() {
+		names = append(names, action.Name().Word())
+	}
+	fmt.Fprintf(s.output, "your move (%v): ", strings.Join(names, ", "))
+}
+
+// ask - reads human actions until an available one is typed
+func (s *session) ask(state games.GameState) (acting.Action, error) {
+	for s.input.Scan() {
+		typed := strings.ToLower(strings.TrimSpace(s.input.Text()))
+		if typed == "quit" || typed == "q" {
+			return nil, errQuit
+		}
+		for _, action := range state.Actions() {
+			description := action.Name().Word()
+			if typed == description || typed == strings.ToLower(action.Name().String()) || (typed != "" && typed == description[:1] && action.Name() != acting.Check) {
+				return action, nil
+			}
+		}
+		fmt.Fprintf(s.output, "%q is not available, try again: ", typed)
+	}
+	if err := s.input.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func boardString(state games.PokerGameState) string {
+	if len(state.Table().Cards) == 0 {
+		return "-"
+	}
+	board := []string{}
+	for _, card := range state.Table().Cards {
+		board = append(board, card.String())
+	}
+	return strings.Join(board, " ")
+}
+
+func seatName(id acting.ActorID) string {
+	if id == acting.PlayerA {
+		return "A (first to act)"
+	}
+	return "B"
+}
+
+func resultVerb(winnings float32) string {
+	if winnings < 0 {
+		return "lose"
+	}
+	return "win"
+}
+
+func abs(value float32) float32 {
+	if value < 0 {
+		return -value
+	}
+	if value == 0 {
+		// negative zero of split pots is printed as -0 otherwise
+		return 0
+	}
+	return value
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/int8/go-counterfactual-regret-minimization/games/bundled"
+	"github.com/int8/go-counterfactual-regret-minimization/policy"
+)
+
+func TestSessionPlaysHandsAgainstBot(t *testing.T) {
+	root, err := bundled.Config{Game: bundled.RhodeIsland, MinCard: 10, MaxRaises: 1}.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := &bytes.Buffer{}
+	input := strings.Repeat("hello\nch\nc\n", 100)
+	s := newSession(root, policy.AlwaysRaise, strings.NewReader(input), output, 1)
+	s.play(4)
+
+	if strings.Count(output.String(), "hand #") != 4 {
+		t.Errorf("4 hands should be played, got:\n%v", output.String())
+	}
+	if !strings.Contains(output.String(), "your card") || !strings.Contains(output.String(), "pot") {
+		t.Errorf("Human should see own card and pot, got:\n%v", output.String())
+	}
+	if !strings.Contains(output.String(), `"hello" is not available`) {
+		t.Errorf("Unknown actions should be rejected, got:\n%v", output.String())
+	}
+	if !strings.Contains(output.String(), "bot: bet") {
+		t.Errorf("Aggressive bot should bet, got:\n%v", output.String())
+	}
+	if !strings.Contains(output.String(), "session over") {
+		t.Errorf("Session should end with bankroll summary, got:\n%v", output.String())
+	}
+}
+
+func TestSessionEndsOnQuit(t *testing.T) {
+	root, err := bundled.Config{Game: bundled.Kuhn}.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := &bytes.Buffer{}
+	s := newSession(root, policy.AlwaysCall, strings.NewReader("quit\n"), output, 1)
+	s.play(0)
+
+	if s.bankroll != 0 || !strings.Contains(output.String(), "session over, bankroll 0") {
+		t.Errorf("Quitting in the first hand should leave bankroll untouched, got:\n%v", output.String())
+	}
+}
+
+func TestSplitPotIsPrintedAsZero(t *testing.T) {
+	for _, value := range []float32{0, float32(math.Copysign(0, -1))} {
+		if printed := fmt.Sprint(abs(value)); printed != "0" {
+			t.Errorf("Split pot should be printed as 0, got %v", printed)
+		}
+	}
+	if abs(-2) != 2 || abs(3) != 3 {
+		t.Errorf("abs should drop the sign, got %v %v", abs(-2), abs(3))
+	}
+}
@@ -0,0 +1,55 @@
+// Command server answers strategy queries of a trained bot over HTTP
+//
+//	server --strategy strategy.bin --addr localhost:8080
+//	curl -d '{"card": {"rank": "K", "suit": "♥"}, "history": ["check"]}' localhost:8080/query
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/int8/go-counterfactual-regret-minimization/games/bundled"
+	"github.com/int8/go-counterfactual-regret-minimization/server"
+)
+
+func main() {
+	strategyFile := flag.String("strategy", "strategy.bin", "strategy file written by cfr train")
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	flag.Parse()
+
+	config, strategy, err := bundled.LoadFile(*strategyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	handler, err := server.New(config, strategy)
+	if err != nil {
+		log.Fatal(err)
+	}
+	httpServer := &http.Server{Addr: *addr, Handler: handler}
+
+	done := make(chan struct{})
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		// requests in flight are given some time to finish
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(ctx); err != nil {
+			log.Print(err)
+		}
+		close(done)
+	}()
+
+	log.Printf("serving %v strategy (%v information sets) on %v", config, len(strategy.Value), *addr)
+	if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+	<-done
+}
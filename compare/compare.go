@@ -0,0 +1,191 @@
+// Package compare - differences between two strategies of the same game
+package compare
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/rounds"
+)
+
+// smoothing - probability given to actions never played when computing KL divergence
+const smoothing = 1e-6
+
+// InformationSetDistance - distance between action frequencies of both strategies in an information set
+type InformationSetDistance struct {
+	InformationSet games.InformationSet
+	Description    string
+	Round          string
+	L1             float64
+	// KL - Kullback-Leibler divergence of second strategy from the first one
+	KL float64
+}
+
+// RoundDistance - mean distances over information sets of a round present in both strategies
+type RoundDistance struct {
+	Round           string
+	InformationSets int
+	MeanL1          float64
+	MeanKL          float64
+	MaxL1           float64
+	round           int
+}
+
+// Diff - information sets present in only one strategy and distances of common ones (sorted by L1 distance, largest first)
+type Diff struct {
+	OnlyFirst  []string
+	OnlySecond []string
+	Common     []InformationSetDistance
+	Rounds     []RoundDistance
+}
+
+// Values - exploitability of both strategies and expected value of the first one against the second (both seats averaged)
+type Values struct {
+	ExploitabilityFirst  float32
+	ExploitabilitySecond float32
+	HeadToHead           float64
+}
+
+// Strategies - aligns two strategies of game (root of its game tree) by information set
+func Strategies(game games.GameState, first cfr.StrategyMap, second cfr.StrategyMap) Diff {
+	diff := Diff{}
+	byRound := map[int]*RoundDistance{}
+	for infSet, firstActions := range first.Value {
+		secondActions, ok := second.Value[infSet]
+		if !ok {
+			diff.OnlyFirst = append(diff.OnlyFirst, games.DescribeInformationSet(game, infSet))
+			continue
+		}
+		distance, round := describe(game, infSet)
+		p, q := cfr.Normalize(firstActions), cfr.Normalize(secondActions)
+		for name := range union(p, q) {
+			distance.L1 += math.Abs(p[name] - q[name])
+			if p[name] > 0 {
+				distance.KL += p[name] * math.Log(p[name]/math.Max(q[name], smoothing))
+			}
+		}
+		diff.Common = append(diff.Common, distance)
+
+		if byRound[round] == nil {
+			byRound[round] = &RoundDistance{Round: distance.Round, round: round}
+		}
+		byRound[round].InformationSets++
+		byRound[round].MeanL1 += distance.L1
+		byRound[round].MeanKL += distance.KL
+		byRound[round].MaxL1 = math.Max(byRound[round].MaxL1, distance.L1)
+	}
+	for infSet := range second.Value {
+		if _, ok := first.Value[infSet]; !ok {
+			diff.OnlySecond = append(diff.OnlySecond, games.DescribeInformationSet(game, infSet))
+		}
+	}
+	for _, round := range byRound {
+		round.MeanL1 /= float64(round.InformationSets)
+		round.MeanKL /= float64(round.InformationSets)
+		diff.Rounds = append(diff.Rounds, *round)
+	}
+
+	sort.Strings(diff.OnlyFirst)
+	sort.Strings(diff.OnlySecond)
+	sort.Slice(diff.Common, func(i, j int) bool {
+		if diff.Common[i].L1 != diff.Common[j].L1 {
+			return diff.Common[i].L1 > diff.Common[j].L1
+		}
+		return diff.Common[i].Description < diff.Common[j].Description
+	})
+	sort.Slice(diff.Rounds, func(i, j int) bool { return diff.Rounds[i].round < diff.Rounds[j].round })
+	return diff
+}
+
+// StrategyValues - exploitability of both strategies and their head-to-head value, traverses whole game tree (a few times)
+func StrategyValues(root games.GameState, first cfr.StrategyMap, second cfr.StrategyMap) Values {
+	return Values{
+		ExploitabilityFirst:  cfr.Exploitability(root, first),
+		ExploitabilitySecond: cfr.Exploitability(root, second),
+		HeadToHead:           (expectedValue(root, first, second) - expectedValue(root, second, first)) / 2,
+	}
+}
+
+// Write - readable summary of diff, top information sets with largest L1 distance are listed
+func (diff Diff) Write(w io.Writer, top int) {
+	fmt.Fprintf(w, "%v common information sets, %v only in first strategy, %v only in second strategy\n", len(diff.Common), len(diff.OnlyFirst), len(diff.OnlySecond))
+	writeList(w, "only in first", diff.OnlyFirst, top)
+	writeList(w, "only in second", diff.OnlySecond, top)
+	for _, round := range diff.Rounds {
+		fmt.Fprintf(w, "%v: %v information sets, mean L1 %.4f, max L1 %.4f, mean KL %.4f\n", round.Round, round.InformationSets, round.MeanL1, round.MaxL1, round.MeanKL)
+	}
+	if top > 0 && len(diff.Common) > 0 {
+		fmt.Fprintln(w, "largest differences:")
+		for i := 0; i < top && i < len(diff.Common); i++ {
+			fmt.Fprintf(w, "  %v  L1 %.4f  KL %.4f\n", diff.Common[i].Description, diff.Common[i].L1, diff.Common[i].KL)
+		}
+	}
+}
+
+func (values Values) Write(w io.Writer) {
+	fmt.Fprintf(w, "exploitability: first %.6f, second %.6f\n", values.ExploitabilityFirst, values.ExploitabilitySecond)
+	fmt.Fprintf(w, "first against second: %.6f per hand\n", values.HeadToHead)
+}
+
+func writeList(w io.Writer, title string, descriptions []string, top int) {
+	for i := 0; i < top && i < len(descriptions); i++ {
+		fmt.Fprintf(w, "  %v: %v\n", title, descriptions[i])
+	}
+}
+
+func describe(game games.GameState, infSet games.InformationSet) (InformationSetDistance, int) {
+	distance := InformationSetDistance{InformationSet: infSet, Description: fmt.Sprintf("%v", infSet), Round: "-"}
+	describer, ok := game.(games.InformationSetDescriber)
+	if !ok {
+		return distance, 0
+	}
+	description, err := describer.DescribeInformationSet(infSet)
+	if err != nil {
+		return distance, 0
+	}
+	distance.Description = description.String()
+	distance.Round = rounds.PokerRound(len(description.Rounds)).String()
+	return distance, len(description.Rounds)
+}
+
+// expectedValue - value of player A playing strategyA against player B playing strategyB, unseen information sets are played uniformly
+func expectedValue(state games.GameState, strategyA cfr.StrategyMap, strategyB cfr.StrategyMap) float64 {
+	if state.IsTerminal() {
+		return float64(state.Evaluate())
+	}
+	actions := state.Actions()
+	var probabilities []float64
+	switch state.CurrentActor().GetID() {
+	case acting.ChanceId:
+		for _, probability := range games.ChanceProbabilities(state, actions) {
+			probabilities = append(probabilities, float64(probability))
+		}
+	case acting.PlayerA:
+		probabilities = cfr.StrategyProbabilities(strategyA, state.InformationSet(), actions)
+	default:
+		probabilities = cfr.StrategyProbabilities(strategyB, state.InformationSet(), actions)
+	}
+	value := 0.
+	for i, action := range actions {
+		if probabilities[i] > 0 {
+			value += probabilities[i] * expectedValue(state.Act(action), strategyA, strategyB)
+		}
+	}
+	return value
+}
+
+func union(p map[acting.ActionName]float64, q map[acting.ActionName]float64) map[acting.ActionName]bool {
+	names := map[acting.ActionName]bool{}
+	for name := range p {
+		names[name] = true
+	}
+	for name := range q {
+		names[name] = true
+	}
+	return names
+}
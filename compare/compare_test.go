@@ -0,0 +1,96 @@
+package compare
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/bundled"
+	"github.com/int8/go-counterfactual-regret-minimization/sequenceform"
+)
+
+func TestIdenticalStrategies(t *testing.T) {
+	root := createKuhnRootForTest(t)
+	strategy := equilibriumForTest(t, root)
+
+	diff := Strategies(root, strategy, strategy)
+	if len(diff.Common) != 12 || len(diff.OnlyFirst) != 0 || len(diff.OnlySecond) != 0 {
+		t.Fatalf("Strategy should match itself in all 12 information sets, got %v", len(diff.Common))
+	}
+	for _, distance := range diff.Common {
+		if distance.L1 > 1e-9 || math.Abs(distance.KL) > 1e-9 {
+			t.Errorf("Strategy should not differ from itself in %v", distance.Description)
+		}
+	}
+	values := StrategyValues(root, strategy, strategy)
+	if math.Abs(values.HeadToHead) > 1e-6 || values.ExploitabilityFirst != values.ExploitabilitySecond {
+		t.Errorf("Strategy should break even against itself, got %v", values.HeadToHead)
+	}
+}
+
+func TestEquilibriumAgainstUniform(t *testing.T) {
+	root := createKuhnRootForTest(t)
+	ne := equilibriumForTest(t, root)
+	uniform := cfr.NewStrategyMap()
+	for infSet, actions := range ne.Value {
+		uniform.Value[infSet] = map[acting.ActionName]float32{}
+		for name := range actions {
+			uniform.Value[infSet][name] = 1. / float32(len(actions))
+		}
+	}
+	var missing games.InformationSet
+	for infSet := range uniform.Value {
+		missing = infSet
+		break
+	}
+	delete(uniform.Value, missing)
+
+	diff := Strategies(root, ne, uniform)
+	if len(diff.Common) != 11 || len(diff.OnlyFirst) != 1 || len(diff.OnlySecond) != 0 {
+		t.Errorf("One information set should be missing in the second strategy, got %v %v", diff.OnlyFirst, diff.OnlySecond)
+	}
+	if len(diff.Rounds) != 1 || diff.Rounds[0].Round != "Preflop" || diff.Rounds[0].InformationSets != 11 {
+		t.Errorf("Kuhn Poker has single round, got %+v", diff.Rounds)
+	}
+	for i := 1; i < len(diff.Common); i++ {
+		if diff.Common[i].L1 > diff.Common[i-1].L1 {
+			t.Errorf("Information sets should be sorted by L1 distance")
+		}
+	}
+	if diff.Common[0].L1 < 0.9 || diff.Common[0].KL <= 0 {
+		t.Errorf("Some equilibrium actions are (almost) pure, largest L1 should be close to 1, got %v", diff.Common[0].L1)
+	}
+
+	values := StrategyValues(root, ne, uniform)
+	if values.HeadToHead <= 0 || values.ExploitabilityFirst > 0.01 || values.ExploitabilitySecond < 0.4 {
+		t.Errorf("Equilibrium should beat uniform strategy and be less exploitable, got %+v", values)
+	}
+
+	buffer := &bytes.Buffer{}
+	diff.Write(buffer, 3)
+	values.Write(buffer)
+	if !strings.Contains(buffer.String(), "11 common information sets, 1 only in first strategy") || strings.Count(buffer.String(), "  L1 ") != 3 {
+		t.Errorf("Summary should list counts and top differences, got:\n%v", buffer.String())
+	}
+}
+
+// equilibriumForTest - exact equilibrium of the sequence form, unlike CFR it does not depend on math/rand global source
+func equilibriumForTest(t *testing.T, root games.GameState) cfr.StrategyMap {
+	solution, err := sequenceform.Solve(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return solution.Strategy
+}
+
+func createKuhnRootForTest(t *testing.T) games.GameState {
+	root, err := bundled.Config{Game: bundled.Kuhn}.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
@@ -0,0 +1,338 @@
+// Package distributed - CFR training spread over processes
+//
+// Coordinator keeps regret and strategy sum tables of a cfr.ComputingRoutine and hands out batches of iterations over
+// net/rpc. Worker traverses the game tree against the snapshot of the current strategy sent with the batch and returns
+// increments of regrets and strategy sums, coordinator merges them and sends the updated strategy with the next batch.
+// Batches of workers which disconnect (or exceed Coordinator.Timeout) are handed out again.
+// Information set types travel with encoding/gob and have to be registered (games of the repository do it themselves).
+package distributed
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/rpc"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+)
+
+// serviceName - name of the coordinator RPC service
+const serviceName = "Coordinator"
+
+// BatchRequest - worker asks for work, Version is the version of strategy it holds (-1 for none)
+type BatchRequest struct {
+	Game    string
+	Version int
+}
+
+// Batch - iterations for a worker along with the strategy to play, Strategy is left out when worker already has its version
+type Batch struct {
+	ID         int
+	Iterations int
+	Seed       int64
+	Version    int
+	Strategy   []byte
+	// Done - all iterations are handed out, worker can stop
+	Done bool
+}
+
+// Result - increments of regrets and strategy sums of a batch, written by cfr.WriteStrategyMap
+type Result struct {
+	// ID - of the batch
+	ID           int
+	Iterations   int
+	Regrets      []byte
+	StrategySums []byte
+}
+
+// Coordinator - merges results of workers into the tables of a routine
+type Coordinator struct {
+	// Game - description of the game being trained, workers of other games are refused
+	Game string
+	// Progress - called with number of merged iterations after every batch, may be nil
+	Progress func(merged int)
+	// Timeout - batch not returned in time is handed out again, batches are waited for as long as their worker is
+	// connected when 0. Result of the batch coming late is dropped if it is merged from another worker already.
+	Timeout    time.Duration
+	routine    *cfr.ComputingRoutine
+	iterations int
+	batch      int
+	seeds      *rand.Rand
+	assigned   int
+	merged     int
+	// version - number of results merged so far, snapshot is strategy of snapshotVersion
+	version         int
+	snapshot        []byte
+	snapshotVersion int
+	// outstanding - batches handed out and not merged yet by their ID, pending - the ones to be handed out again
+	outstanding map[int]*assignment
+	pending     []Batch
+	workers     int
+	mutex       *sync.Mutex
+	// changed - signalled when a batch is merged or becomes pending, workers wait for it once all batches are handed out
+	changed *sync.Cond
+	done    chan struct{}
+}
+
+// assignment - batch handed out to a worker
+type assignment struct {
+	batch    Batch
+	worker   int
+	deadline time.Time
+}
+
+// NewCoordinator - coordinator running iterations of routine in batches of given size, seed fixes chance sampling of workers
+func NewCoordinator(game string, routine *cfr.ComputingRoutine, iterations int, batch int, seed int64) (*Coordinator, error) {
+	if iterations < 1 || batch < 1 {
+		return nil, errors.New("distributed: iterations and batch size have to be positive")
+	}
+	mutex := &sync.Mutex{}
+	return &Coordinator{Game: game, routine: routine, iterations: iterations, batch: batch, seeds: rand.New(rand.NewSource(seed)),
+		snapshotVersion: -1, outstanding: map[int]*assignment{}, mutex: mutex, changed: sync.NewCond(mutex), done: make(chan struct{})}, nil
+}
+
+// Serve - serves workers connecting to listener until all iterations are merged, listener is closed then. Batches of a
+// worker are handed out again once its connection is closed, Serve keeps waiting for workers as long as some are left.
+func (coordinator *Coordinator) Serve(listener net.Listener) (cfr.StrategyMap, error) {
+	go func() {
+		// rpc.Server.Accept logs closing of the listener
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			// server per connection, so that batches can be taken back from the worker once it disconnects
+			worker := coordinator.connect()
+			server := rpc.NewServer()
+			if err := server.RegisterName(serviceName, &service{coordinator, worker}); err != nil {
+				conn.Close()
+				continue
+			}
+			go func() {
+				server.ServeConn(conn)
+				coordinator.release(worker)
+			}()
+		}
+	}()
+	<-coordinator.done
+	listener.Close()
+	coordinator.mutex.Lock()
+	defer coordinator.mutex.Unlock()
+	return coordinator.routine.AverageStrategy(), nil
+}
+
+func (coordinator *Coordinator) connect() int {
+	coordinator.mutex.Lock()
+	defer coordinator.mutex.Unlock()
+	coordinator.workers++
+	return coordinator.workers
+}
+
+// release - batches of disconnected worker become pending
+func (coordinator *Coordinator) release(worker int) {
+	coordinator.mutex.Lock()
+	defer coordinator.mutex.Unlock()
+	for _, id := range coordinator.outstandingIDs() {
+		if coordinator.outstanding[id].worker == worker {
+			coordinator.reassign(id)
+		}
+	}
+}
+
+// expire - batches past their deadline become pending
+func (coordinator *Coordinator) expire(now time.Time) {
+	for _, id := range coordinator.outstandingIDs() {
+		if deadline := coordinator.outstanding[id].deadline; !deadline.IsZero() && now.After(deadline) {
+			coordinator.reassign(id)
+		}
+	}
+}
+
+// outstandingIDs - IDs of outstanding batches in order they were created, so that they are handed out again in that order
+func (coordinator *Coordinator) outstandingIDs() []int {
+	ids := make([]int, 0, len(coordinator.outstanding))
+	for id := range coordinator.outstanding {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (coordinator *Coordinator) reassign(id int) {
+	coordinator.pending = append(coordinator.pending, coordinator.outstanding[id].batch)
+	delete(coordinator.outstanding, id)
+	coordinator.changed.Broadcast()
+}
+
+// next - next batch for worker holding strategy of given version, waits while all batches are handed out and some of them
+// may still be handed out again
+func (coordinator *Coordinator) next(worker int, request BatchRequest) (Batch, error) {
+	coordinator.mutex.Lock()
+	defer coordinator.mutex.Unlock()
+	if request.Game != coordinator.Game {
+		return Batch{}, fmt.Errorf("distributed: coordinator trains %v, worker %v", coordinator.Game, request.Game)
+	}
+	for {
+		if coordinator.merged >= coordinator.iterations {
+			return Batch{Done: true}, nil
+		}
+		coordinator.expire(time.Now())
+		if len(coordinator.pending) > 0 || coordinator.assigned < coordinator.iterations {
+			break
+		}
+		coordinator.changed.Wait()
+	}
+
+	var batch Batch
+	if len(coordinator.pending) > 0 {
+		batch, coordinator.pending = coordinator.pending[0], coordinator.pending[1:]
+	} else {
+		batch = Batch{ID: coordinator.assigned, Iterations: coordinator.batch, Seed: coordinator.seeds.Int63()}
+		if remaining := coordinator.iterations - coordinator.assigned; remaining < batch.Iterations {
+			batch.Iterations = remaining
+		}
+		coordinator.assigned += batch.Iterations
+	}
+	handedOut := &assignment{batch: batch, worker: worker}
+	if coordinator.Timeout > 0 {
+		handedOut.deadline = time.Now().Add(coordinator.Timeout)
+		// waiting workers check deadlines once it passes
+		time.AfterFunc(coordinator.Timeout, func() {
+			coordinator.mutex.Lock()
+			defer coordinator.mutex.Unlock()
+			coordinator.changed.Broadcast()
+		})
+	}
+	coordinator.outstanding[batch.ID] = handedOut
+
+	batch.Version = coordinator.version
+	if request.Version != coordinator.version {
+		if coordinator.snapshotVersion != coordinator.version {
+			buffer := &bytes.Buffer{}
+			if err := cfr.WriteStrategyMap(buffer, coordinator.routine.Snapshot()); err != nil {
+				return Batch{}, err
+			}
+			coordinator.snapshot, coordinator.snapshotVersion = buffer.Bytes(), coordinator.version
+		}
+		batch.Strategy = coordinator.snapshot
+	}
+	return batch, nil
+}
+
+// merge - adds increments of a batch to the tables of the routine, returns number of iterations merged so far
+func (coordinator *Coordinator) merge(result Result) (int, error) {
+	regrets, err := cfr.ReadStrategyMap(bytes.NewReader(result.Regrets))
+	if err != nil {
+		return 0, fmt.Errorf("distributed: reading regrets: %v", err)
+	}
+	sigmaSums, err := cfr.ReadStrategyMap(bytes.NewReader(result.StrategySums))
+	if err != nil {
+		return 0, fmt.Errorf("distributed: reading strategy sums: %v", err)
+	}
+	coordinator.mutex.Lock()
+	defer coordinator.mutex.Unlock()
+	if !coordinator.complete(result.ID) {
+		// batch was handed out again and merged from another worker
+		return coordinator.merged, nil
+	}
+	coordinator.routine.Apply(regrets, sigmaSums)
+	coordinator.version++
+	coordinator.merged += result.Iterations
+	if coordinator.Progress != nil {
+		coordinator.Progress(coordinator.merged)
+	}
+	if coordinator.merged >= coordinator.iterations {
+		close(coordinator.done)
+	}
+	coordinator.changed.Broadcast()
+	return coordinator.merged, nil
+}
+
+// complete - takes batch out of outstanding (or pending) ones, false when it is not there (merged already)
+func (coordinator *Coordinator) complete(id int) bool {
+	if _, ok := coordinator.outstanding[id]; ok {
+		delete(coordinator.outstanding, id)
+		return true
+	}
+	for i, batch := range coordinator.pending {
+		if batch.ID == id {
+			coordinator.pending = append(coordinator.pending[:i], coordinator.pending[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// service - RPC methods of coordinator for the worker of a connection
+type service struct {
+	coordinator *Coordinator
+	worker      int
+}
+
+func (s *service) Next(request BatchRequest, batch *Batch) error {
+	next, err := s.coordinator.next(s.worker, request)
+	*batch = next
+	return err
+}
+
+func (s *service) Submit(result Result, merged *int) error {
+	count, err := s.coordinator.merge(result)
+	*merged = count
+	return err
+}
+
+// Work - runs batches of coordinator listening on address with routine of the same game (its tables are not used)
+// until all iterations are merged, returns number of iterations run
+func Work(address string, game string, routine *cfr.ComputingRoutine) (int, error) {
+	client, err := rpc.Dial("tcp", address)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+
+	done := 0
+	version := -1
+	var strategy cfr.StrategyMap
+	for {
+		batch := Batch{}
+		if err := client.Call(serviceName+".Next", BatchRequest{Game: game, Version: version}, &batch); err != nil {
+			return done, err
+		}
+		if batch.Done {
+			return done, nil
+		}
+		if batch.Strategy != nil {
+			if strategy, err = cfr.ReadStrategyMap(bytes.NewReader(batch.Strategy)); err != nil {
+				return done, fmt.Errorf("distributed: reading strategy: %v", err)
+			}
+			version = batch.Version
+		}
+
+		regrets, sigmaSums := routine.Traverse(strategy, batch.Iterations, batch.Seed)
+		result := Result{ID: batch.ID, Iterations: batch.Iterations}
+		if result.Regrets, err = encode(regrets); err != nil {
+			return done, err
+		}
+		if result.StrategySums, err = encode(sigmaSums); err != nil {
+			return done, err
+		}
+		var merged int
+		if err := client.Call(serviceName+".Submit", result, &merged); err != nil {
+			return done, err
+		}
+		done += batch.Iterations
+	}
+}
+
+func encode(strategy cfr.StrategyMap) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	if err := cfr.WriteStrategyMap(buffer, strategy); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
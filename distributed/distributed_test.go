@@ -0,0 +1,136 @@
+package distributed
+
+import (
+	"net"
+	"net/rpc"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games/kuhn"
+)
+
+func TestWorkersOverLoopbackTrainKuhnPoker(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := kuhn.NewRoot(1000.)
+	coordinator, err := NewCoordinator("kuhn", cfr.CreateComputingRoutine(root), 30000, 20, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := 0
+	coordinator.Progress = func(iterations int) { merged = iterations }
+
+	group := &sync.WaitGroup{}
+	counts := make([]int, 3)
+	for i := range counts {
+		group.Add(1)
+		go func(i int) {
+			defer group.Done()
+			// every worker builds its own game tree, like a separate process would
+			var workErr error
+			counts[i], workErr = Work(listener.Addr().String(), "kuhn", cfr.CreateComputingRoutine(kuhn.NewRoot(1000.)))
+			if workErr != nil {
+				t.Error(workErr)
+			}
+		}(i)
+	}
+	strategy, err := coordinator.Serve(listener)
+	group.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if counts[0]+counts[1]+counts[2] != 30000 || merged != 30000 {
+		t.Errorf("Exactly 30000 iterations should be run and merged, got %v and %v", counts, merged)
+	}
+	if exploitability := cfr.Exploitability(root, strategy); exploitability < 0 || exploitability > 0.02 {
+		t.Errorf("Merged strategy should approximate Nash equilibrium, got exploitability %v", exploitability)
+	}
+}
+
+func TestWorkerOfOtherGameIsRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	coordinator, err := NewCoordinator("kuhn", cfr.CreateComputingRoutine(kuhn.NewRoot(1000.)), 10, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go coordinator.Serve(listener)
+	defer listener.Close()
+
+	if _, err := Work(listener.Addr().String(), "rhodeisland", cfr.CreateComputingRoutine(kuhn.NewRoot(1000.))); err == nil {
+		t.Error("Worker training another game should be refused")
+	}
+	if _, err := NewCoordinator("kuhn", cfr.CreateComputingRoutine(kuhn.NewRoot(1000.)), 10, 0, 1); err == nil {
+		t.Error("Empty batches should be rejected")
+	}
+}
+
+func TestBatchesOfLostWorkersAreHandedOutAgain(t *testing.T) {
+	for _, disconnect := range []bool{true, false} {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		coordinator, err := NewCoordinator("kuhn", cfr.CreateComputingRoutine(kuhn.NewRoot(1000.)), 40, 20, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !disconnect {
+			coordinator.Timeout = 50 * time.Millisecond
+		}
+		merged := 0
+		coordinator.Progress = func(iterations int) { merged = iterations }
+		served := make(chan error, 1)
+		go func() {
+			_, err := coordinator.Serve(listener)
+			served <- err
+		}()
+
+		// worker taking a batch and never returning it, either disconnecting or hanging
+		lost, err := rpc.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		batch := Batch{}
+		if err := lost.Call(serviceName+".Next", BatchRequest{Game: "kuhn", Version: -1}, &batch); err != nil {
+			t.Fatal(err)
+		}
+		if disconnect {
+			lost.Close()
+		}
+
+		done, err := Work(listener.Addr().String(), "kuhn", cfr.CreateComputingRoutine(kuhn.NewRoot(1000.)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := <-served; err != nil {
+			t.Fatal(err)
+		}
+		if done != 40 || merged != 40 {
+			t.Errorf("Batch of lost worker should be run by another one (disconnected %v), got %v run and %v merged", disconnect, done, merged)
+		}
+
+		if !disconnect {
+			// late result of the batch merged from another worker is dropped
+			empty, err := encode(cfr.NewStrategyMap())
+			if err != nil {
+				t.Fatal(err)
+			}
+			count := 0
+			if err := lost.Call(serviceName+".Submit", Result{ID: batch.ID, Iterations: batch.Iterations, Regrets: empty, StrategySums: empty}, &count); err != nil {
+				t.Fatal(err)
+			}
+			if count != 40 || merged != 40 {
+				t.Errorf("Late result should not be merged, got %v merged", count)
+			}
+			lost.Close()
+		}
+	}
+}
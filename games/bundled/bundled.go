@@ -0,0 +1,135 @@
+package bundled
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/kuhn"
+	"github.com/int8/go-counterfactual-regret-minimization/games/rhodeisland"
+)
+
+const (
+	Kuhn        = "kuhn"
+	RhodeIsland = "rhodeisland"
+)
+
+// DefaultStack - stack of both players unless configured otherwise
+const DefaultStack float32 = 1000.
+
+var symbols = []cards.CardSymbol{cards.C2, cards.C3, cards.C4, cards.C5, cards.C6, cards.C7, cards.C8,
+	cards.C9, cards.C10, cards.Jack, cards.Queen, cards.King, cards.Ace}
+
+// Config - identifies one of the games bundled with the repository along with its rules
+type Config struct {
+	Game string
+	// MinCard - lowest card rank in Rhode Island deck (2-14, 11-14 stand for J Q K A), full deck when 2 or less
+	MinCard int
+	// MaxRaises - maximal number of raises per betting round in Rhode Island
+	MaxRaises int
+	Stack     float32
+	// BitmaskDeck - cards.BitmaskDeck instead of map based decks, the game itself stays the same
+	BitmaskDeck bool
+}
+
+// Root - root of the configured game, note it sets rhodeisland.MaxRaises
+func (config Config) Root() (games.GameState, error) {
+	stack := config.Stack
+	if stack <= 0 {
+		stack = DefaultStack
+	}
+	switch config.Game {
+	case Kuhn:
+		playerA := &kuhn.Player{Id: acting.PlayerA, Actions: nil, Card: nil, Stack: stack}
+		playerB := &kuhn.Player{Id: acting.PlayerB, Actions: nil, Card: nil, Stack: stack}
+		if config.BitmaskDeck {
+			return kuhn.RootWithDeck(playerA, playerB, kuhn.CreateKuhnBitmaskDeck()), nil
+		}
+		return kuhn.Root(playerA, playerB), nil
+	case RhodeIsland:
+		if config.MinCard > 14 || config.MaxRaises < 0 {
+			return nil, fmt.Errorf("bundled: invalid rhodeisland rules, min card %v, max raises %v", config.MinCard, config.MaxRaises)
+		}
+		rhodeisland.MaxRaises = config.MaxRaises
+		playerA := &rhodeisland.Player{Id: acting.PlayerA, Actions: nil, Card: nil, Stack: stack}
+		playerB := &rhodeisland.Player{Id: acting.PlayerB, Actions: nil, Card: nil, Stack: stack}
+		return rhodeisland.Root(playerA, playerB, config.Deck()), nil
+	}
+	return nil, fmt.Errorf("bundled: unknown game %q", config.Game)
+}
+
+// Deck - shuffled Rhode Island deck of configured size
+func (config Config) Deck() cards.Deck {
+	if config.BitmaskDeck {
+		if config.MinCard > 2 {
+			return cards.CreateBitmaskDeck(symbols[config.MinCard-2], true)
+		}
+		return cards.CreateBitmaskDeck(cards.C2, true)
+	}
+	if config.MinCard > 2 {
+		return cards.CreateLimitedDeck(symbols[config.MinCard-2], true)
+	}
+	return cards.CreateFullDeck(true)
+}
+
+func (config Config) String() string {
+	if config.Game == RhodeIsland {
+		return fmt.Sprintf("%v (min card %v, max raises %v)", config.Game, config.MinCard, config.MaxRaises)
+	}
+	return config.Game
+}
+
+// Save - writes strategy file: game config followed by the strategy
+func Save(w io.Writer, config Config, strategy cfr.StrategyMap) error {
+	if err := gob.NewEncoder(w).Encode(config); err != nil {
+		return err
+	}
+	return cfr.WriteStrategyMap(w, strategy)
+}
+
+// Load - reads strategy file written by Save
+func Load(r io.Reader) (Config, cfr.StrategyMap, error) {
+	// decoders do not read ahead from io.ByteReader, so both can share it
+	reader := bufio.NewReader(r)
+	config := Config{}
+	if err := gob.NewDecoder(reader).Decode(&config); err != nil {
+		return Config{}, cfr.StrategyMap{}, fmt.Errorf("bundled: reading strategy file: %v", err)
+	}
+	strategy, err := cfr.ReadStrategyMap(reader)
+	if err != nil {
+		return Config{}, cfr.StrategyMap{}, fmt.Errorf("bundled: reading strategy file: %v", err)
+	}
+	return config, strategy, nil
+}
+
+func SaveFile(path string, config Config, strategy cfr.StrategyMap) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	if err := Save(writer, config, strategy); err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func LoadFile(path string) (Config, cfr.StrategyMap, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Config{}, cfr.StrategyMap{}, err
+	}
+	defer file.Close()
+	return Load(file)
+}
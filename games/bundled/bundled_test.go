@@ -0,0 +1,85 @@
+package bundled
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games/rhodeisland"
+)
+
+func TestRhodeIslandRootFollowsConfig(t *testing.T) {
+	root, err := Config{Game: RhodeIsland, MinCard: 10, MaxRaises: 2}.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(root.Actions()) != 20*19 {
+		t.Errorf("Deck from 10 up should deal %v pairs of private cards, got %v", 20*19, len(root.Actions()))
+	}
+	if rhodeisland.MaxRaises != 2 {
+		t.Errorf("Root should set max raises to 2, got %v", rhodeisland.MaxRaises)
+	}
+
+	full, err := Config{Game: RhodeIsland, MinCard: 2}.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(full.Actions()) != 52*51 {
+		t.Errorf("Full deck should deal %v pairs of private cards, got %v", 52*51, len(full.Actions()))
+	}
+}
+
+func TestBitmaskDeckTrainsTheSameStrategy(t *testing.T) {
+	defer func(maxRaises int) { rhodeisland.MaxRaises = maxRaises }(rhodeisland.MaxRaises)
+	for _, config := range []Config{{Game: Kuhn}, {Game: RhodeIsland, MinCard: 13, MaxRaises: 0}} {
+		strategies := []cfr.StrategyMap{}
+		for _, bitmask := range []bool{false, true} {
+			config.BitmaskDeck = bitmask
+			root, err := config.Root()
+			if err != nil {
+				t.Fatal(err)
+			}
+			routine := cfr.CreateComputingRoutine(root)
+			strategies = append(strategies, routine.ComputeNashEquilibriumViaTreeParallelCFR(20, 2))
+		}
+		if !reflect.DeepEqual(strategies[0], strategies[1]) {
+			t.Errorf("%v should train the same strategy with bitmask deck", config)
+		}
+	}
+}
+
+func TestUnknownGamesAreRejected(t *testing.T) {
+	for _, config := range []Config{{Game: "holdem"}, {Game: RhodeIsland, MinCard: 15}, {Game: RhodeIsland, MaxRaises: -1}} {
+		if _, err := config.Root(); err == nil {
+			t.Errorf("%v should not have root", config)
+		}
+	}
+}
+
+func TestStrategyFileRoundTrip(t *testing.T) {
+	config := Config{Game: Kuhn, Stack: 10.}
+	root, err := config.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	strategy := cfr.CreateComputingRoutine(root).ComputeNashEquilibriumViaCFR(100, 1)
+
+	buffer := &bytes.Buffer{}
+	if err := Save(buffer, config, strategy); err != nil {
+		t.Fatal(err)
+	}
+	loadedConfig, loadedStrategy, err := Load(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loadedConfig != config {
+		t.Errorf("Config should survive round trip, got %v", loadedConfig)
+	}
+	if !reflect.DeepEqual(loadedStrategy.Value, strategy.Value) {
+		t.Error("Strategy should survive round trip")
+	}
+	if _, _, err := Load(bytes.NewBufferString("not a strategy")); err == nil {
+		t.Error("Garbage should not load")
+	}
+}
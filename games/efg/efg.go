@@ -0,0 +1,113 @@
+package efg
+
+import (
+	"encoding/gob"
+	"math/big"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+)
+
+type nodeKind int8
+
+const (
+	chanceNode nodeKind = iota
+	playerNode
+	terminalNode
+)
+
+// InformationSet - information set of a game imported from .efg file (player and its infoset number)
+type InformationSet struct {
+	Player acting.ActorID
+	Number int
+}
+
+func init() {
+	// strategies of imported games are keyed by player and information set number of the .efg file
+	gob.Register(InformationSet{})
+}
+
+// Action - action of imported game, keeps label from .efg file
+type Action struct {
+	label string
+	name  acting.ActionName
+	index int
+}
+
+func (a Action) Name() acting.ActionName {
+	return a.name
+}
+
+func (a Action) Label() string {
+	return a.label
+}
+
+func (a Action) String() string {
+	return a.label
+}
+
+type actor struct {
+	id acting.ActorID
+}
+
+func (a actor) GetID() acting.ActorID {
+	return a.id
+}
+
+// Node - node of a game tree imported from Gambit .efg file, implements games.GameState
+type Node struct {
+	parent        *Node
+	kind          nodeKind
+	actor         actor
+	infoSet       InformationSet
+	actions       []acting.Action
+	probabilities []float32
+	exact         []*big.Rat
+	children      []*Node
+	payoff        float32
+}
+
+func (node *Node) Parent() games.GameState {
+	if node.parent == nil {
+		return nil
+	}
+	return node.parent
+}
+
+func (node *Node) Act(action acting.Action) games.GameState {
+	a, ok := action.(Action)
+	if !ok || a.index >= len(node.children) || node.actions[a.index] != action {
+		panic("action not available")
+	}
+	return node.children[a.index]
+}
+
+func (node *Node) InformationSet() games.InformationSet {
+	return node.infoSet
+}
+
+func (node *Node) Actions() []acting.Action {
+	return node.actions
+}
+
+func (node *Node) IsTerminal() bool {
+	return node.kind == terminalNode
+}
+
+func (node *Node) CurrentActor() acting.Actor {
+	return node.actor
+}
+
+// Evaluate - payoff of player 1 (acting.PlayerA), games are zero-sum
+func (node *Node) Evaluate() float32 {
+	return node.payoff
+}
+
+func (node *Node) ChanceProbabilities() []float32 {
+	return node.probabilities
+}
+
+// ExactChanceProbabilities - probabilities as written in the .efg file, shared by all nodes of the information set
+func (node *Node) ExactChanceProbabilities() []*big.Rat {
+	return node.exact
+}
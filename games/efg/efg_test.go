@@ -0,0 +1,199 @@
+package efg
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/kuhn"
+)
+
+const myersonCardGame = `EFG 2 R "Myerson's card game" { "Player 1" "Player 2" }
+"red or black card, player 1 may raise or fold"
+
+c "" 1 "" { "Red" 1/2 "Black" 1/2 } 0
+p "" 1 1 "" { "Raise" "Fold" } 0
+p "" 2 1 "" { "Meet" "Pass" } 0
+t "" 1 "Win Big" { 2, -2 }
+t "" 2 "Win" { 1, -1 }
+t "" 3 "" { 1, -1 }
+p "" 1 2 "" { "Raise" "Fold" } 0
+p "" 2 1 0
+t "" 4 "" { -2, 2 }
+t "" 2
+t "" 5 "" { -1, 1 }
+`
+
+func TestImportMyersonCardGame(t *testing.T) {
+	root, err := Import(strings.NewReader(myersonCardGame))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if root.CurrentActor().GetID() != acting.ChanceId || root.Parent() != nil {
+		t.Error("Root should be a chance node without parent")
+	}
+
+	probabilities := games.ChanceProbabilities(root, root.Actions())
+	if len(probabilities) != 2 || probabilities[0] != 0.5 || probabilities[1] != 0.5 {
+		t.Errorf("Red and Black should be dealt with probability 1/2, got %v", probabilities)
+	}
+
+	red := root.Act(root.Actions()[0])
+	black := root.Act(root.Actions()[1])
+	if red.InformationSet() == black.InformationSet() {
+		t.Error("Player 1 sees the card, red and black should be different information sets")
+	}
+
+	meetAfterRed := red.Act(red.Actions()[0])
+	meetAfterBlack := black.Act(black.Actions()[0])
+	if meetAfterRed.InformationSet() != meetAfterBlack.InformationSet() {
+		t.Error("Player 2 does not see the card, both nodes should share information set")
+	}
+	if meetAfterRed.CurrentActor().GetID() != acting.PlayerB {
+		t.Error("Player 2 should be acting.PlayerB")
+	}
+
+	if value := black.Act(black.Actions()[0]).Act(meetAfterBlack.Actions()[1]).Evaluate(); value != 1 {
+		t.Errorf("Reused outcome should pay 1 to player 1, got %v", value)
+	}
+	if label := red.Actions()[1].(Action).Label(); label != "Fold" {
+		t.Errorf("Action label should be kept, got %v", label)
+	}
+}
+
+func TestImportNamesKnownActions(t *testing.T) {
+	game := `EFG 2 R "" { "A" "B" } ""
+p "" 1 1 "" { "Ch" "B" } 0
+t "" 1 "" { 1, -1 }
+t "" 2 "" { -1, 1 }
+`
+	root, err := Import(strings.NewReader(game))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Actions()[0].Name() != acting.Check || root.Actions()[1].Name() != acting.Bet {
+		t.Errorf("Labels of known actions should map to acting names, got %v", root.Actions())
+	}
+}
+
+func TestImportNamesOtherActionsApartFromPokerActions(t *testing.T) {
+	labels, leaves := []string{}, []string{}
+	for i := 0; i < 10; i++ {
+		labels = append(labels, fmt.Sprintf("%q", fmt.Sprintf("move %v", i)))
+		leaves = append(leaves, fmt.Sprintf(`t "" %v "" { %v, -%v }`, i+1, i, i))
+	}
+	game := fmt.Sprintf("EFG 2 R \"\" { \"A\" \"B\" } \"\"\np \"\" 1 1 \"\" { %v } 0\n%v\n", strings.Join(labels, " "), strings.Join(leaves, "\n"))
+	root, err := Import(strings.NewReader(game))
+	if err != nil {
+		t.Fatal(err)
+	}
+	poker := map[acting.ActionName]bool{acting.NoAction: true, acting.DealPublicCards: true, acting.DealPrivateCards: true,
+		acting.Fold: true, acting.Check: true, acting.Bet: true, acting.Call: true, acting.Raise: true}
+	for i, action := range root.Actions() {
+		if index, ok := action.Name().ImportedIndex(); !ok || index != i || poker[action.Name()] {
+			t.Errorf("Action %v should be named by its index apart from poker actions, got %v", i, action.Name())
+		}
+	}
+	if value := root.Act(root.Actions()[9]).Evaluate(); value != 9 {
+		t.Errorf("Tenth action should lead to its own leaf, got payoff %v", value)
+	}
+}
+
+func TestImportRejectsMalformedGames(t *testing.T) {
+	malformed := map[string]string{
+		"non zero-sum":    `EFG 2 R "" { "A" "B" } "" t "" 1 "" { 1, 1 }`,
+		"three players":   `EFG 2 R "" { "A" "B" "C" } "" t "" 1 "" { 1, -1 }`,
+		"missing child":   `EFG 2 R "" { "A" "B" } "" p "" 1 1 "" { "x" "y" } 0 t "" 0`,
+		"unknown outcome": `EFG 2 R "" { "A" "B" } "" t "" 7`,
+		"unterminated":    `EFG 2 R "" { "A" "B" } "`,
+		"negative chance": `EFG 2 R "" { "A" "B" } "" c "" 1 "" { "x" 3/2 "y" -1/2 } 0 t "" 1 "" { 1, -1 } t "" 2 "" { -1, 1 }`,
+		"chance sum":      `EFG 2 R "" { "A" "B" } "" c "" 1 "" { "x" 1/2 "y" 1/3 } 0 t "" 1 "" { 1, -1 } t "" 2 "" { -1, 1 }`,
+		"relabelled": `EFG 2 R "" { "A" "B" } "" c "" 1 "" { "l" 1/2 "r" 1/2 } 0
+p "" 1 1 "" { "x" "y" } 0 t "" 1 "" { 1, -1 } t "" 2 "" { -1, 1 }
+p "" 1 1 "" { "y" "x" } 0 t "" 1 t "" 2`,
+	}
+	for name, game := range malformed {
+		if _, err := Import(strings.NewReader(game)); err == nil {
+			t.Errorf("%v game should not be imported", name)
+		}
+	}
+}
+
+func TestKuhnExportImportRoundTrip(t *testing.T) {
+	root := kuhn.NewRoot(100.)
+
+	buffer := &bytes.Buffer{}
+	if err := Export(buffer, root, "Kuhn poker"); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := Import(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	infoSets := map[games.InformationSet]games.InformationSet{}
+	compareTrees(root, imported, infoSets, t)
+
+	if len(infoSets) != 12 {
+		t.Errorf("Kuhn poker has 12 information sets, %v imported", len(infoSets))
+	}
+	distinct := map[games.InformationSet]bool{}
+	for _, infoSet := range infoSets {
+		distinct[infoSet] = true
+	}
+	if len(distinct) != len(infoSets) {
+		t.Error("Information sets should not merge during round trip")
+	}
+}
+
+func compareTrees(original games.GameState, imported games.GameState, infoSets map[games.InformationSet]games.InformationSet, t *testing.T) {
+	if original.IsTerminal() != imported.IsTerminal() {
+		t.Fatal("Terminal nodes should match")
+	}
+	if original.IsTerminal() {
+		if math.Abs(float64(original.Evaluate()-imported.Evaluate())) > 1e-6 {
+			t.Errorf("Payoffs should match, %v != %v", original.Evaluate(), imported.Evaluate())
+		}
+		return
+	}
+	if original.CurrentActor().GetID() != imported.CurrentActor().GetID() {
+		t.Fatal("Acting players should match")
+	}
+	originalActions, importedActions := original.Actions(), append([]acting.Action{}, imported.Actions()...)
+	if len(originalActions) != len(importedActions) {
+		t.Fatal("Number of actions should match")
+	}
+	if original.CurrentActor().GetID() == acting.ChanceId {
+		for i, probability := range games.ChanceProbabilities(imported, importedActions) {
+			if math.Abs(float64(probability)-1./float64(len(originalActions))) > 1e-6 {
+				t.Errorf("Chance action %v should have uniform probability, got %v", i, probability)
+			}
+		}
+		// deck iteration order is random, chance actions are matched by label
+		labels := map[string]acting.Action{}
+		for _, action := range importedActions {
+			labels[action.(Action).Label()] = action
+		}
+		for i := range originalActions {
+			importedActions[i] = labels[actionLabel(originalActions[i])]
+		}
+	} else {
+		if known, ok := infoSets[original.InformationSet()]; ok && known != imported.InformationSet() {
+			t.Error("States of an information set should map to single imported information set")
+		}
+		infoSets[original.InformationSet()] = imported.InformationSet()
+		for i := range originalActions {
+			if originalActions[i].Name() != importedActions[i].Name() {
+				t.Errorf("Action names should survive round trip, %v != %v", originalActions[i].Name(), importedActions[i].Name())
+			}
+		}
+	}
+	for i := range originalActions {
+		compareTrees(original.Act(originalActions[i]), imported.Act(importedActions[i]), infoSets, t)
+	}
+}
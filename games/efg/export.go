@@ -0,0 +1,122 @@
+package efg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+)
+
+type exporter struct {
+	writer    *bufio.Writer
+	infoSets  map[acting.ActorID]map[games.InformationSet]int
+	actions   map[acting.ActorID]map[games.InformationSet][]acting.ActionName
+	chanceSet int
+	outcome   int
+}
+
+// Export - writes finite game tree rooted at root in Gambit extensive form (.efg) format
+func Export(w io.Writer, root games.GameState, title string) error {
+	e := &exporter{writer: bufio.NewWriter(w),
+		infoSets: map[acting.ActorID]map[games.InformationSet]int{acting.PlayerA: {}, acting.PlayerB: {}},
+		actions:  map[acting.ActorID]map[games.InformationSet][]acting.ActionName{acting.PlayerA: {}, acting.PlayerB: {}}}
+	fmt.Fprintf(e.writer, "EFG 2 R %v { %v %v }\n%v\n\n", quote(title), quote("Player A"), quote("Player B"), quote(""))
+	if err := e.node(root); err != nil {
+		return err
+	}
+	return e.writer.Flush()
+}
+
+func (e *exporter) node(state games.GameState) error {
+	if state.IsTerminal() {
+		e.outcome++
+		payoff := state.Evaluate()
+		_, err := fmt.Fprintf(e.writer, "t \"\" %v \"\" { %v, %v }\n", e.outcome, formatFloat32(payoff), formatFloat32(-payoff))
+		return err
+	}
+
+	actions := state.Actions()
+	if len(actions) == 0 {
+		return errors.New("efg: non-terminal state without actions")
+	}
+
+	if state.CurrentActor().GetID() == acting.ChanceId {
+		e.chanceSet++
+		labels := make([]string, len(actions))
+		probabilities := games.ExactChanceProbabilities(state, actions)
+		for i, action := range actions {
+			labels[i] = quote(actionLabel(action)) + " " + probabilities[i].RatString()
+		}
+		fmt.Fprintf(e.writer, "c \"\" %v \"\" { %v } 0\n", e.chanceSet, strings.Join(labels, " "))
+	} else {
+		player := state.CurrentActor().GetID()
+		number, err := e.infoSet(player, state.InformationSet(), actions)
+		if err != nil {
+			return err
+		}
+		labels := make([]string, len(actions))
+		for i, action := range actions {
+			labels[i] = quote(actionLabel(action))
+		}
+		playerNumber := 1
+		if player == acting.PlayerB {
+			playerNumber = 2
+		}
+		fmt.Fprintf(e.writer, "p \"\" %v %v %v { %v } 0\n", playerNumber, number,
+			quote(fmt.Sprint(state.InformationSet())), strings.Join(labels, " "))
+	}
+
+	for _, action := range actions {
+		if err := e.node(state.Act(action)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// infoSet - number of information set within player's sets, actions have to agree across set's states
+func (e *exporter) infoSet(player acting.ActorID, infoSet games.InformationSet, actions []acting.Action) (int, error) {
+	names := make([]acting.ActionName, len(actions))
+	for i, action := range actions {
+		names[i] = action.Name()
+	}
+	if number, ok := e.infoSets[player][infoSet]; ok {
+		known := e.actions[player][infoSet]
+		if len(known) != len(names) {
+			return 0, fmt.Errorf("efg: information set %v has inconsistent actions", infoSet)
+		}
+		for i := range names {
+			if known[i] != names[i] {
+				return 0, fmt.Errorf("efg: information set %v has inconsistent actions", infoSet)
+			}
+		}
+		return number, nil
+	}
+	number := len(e.infoSets[player]) + 1
+	e.infoSets[player][infoSet] = number
+	e.actions[player][infoSet] = names
+	return number, nil
+}
+
+func actionLabel(action acting.Action) string {
+	if _, ok := action.(fmt.Stringer); ok {
+		return fmt.Sprint(action)
+	}
+	if action.Name() == acting.DealPrivateCards || action.Name() == acting.DealPublicCards {
+		return fmt.Sprint(action)
+	}
+	return action.Name().String()
+}
+
+func formatFloat32(value float32) string {
+	return strconv.FormatFloat(float64(value), 'g', -1, 32)
+}
+
+func quote(text string) string {
+	return `"` + strings.Replace(strings.Replace(text, `\`, `\\`, -1), `"`, `\"`, -1) + `"`
+}
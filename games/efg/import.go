@@ -0,0 +1,448 @@
+package efg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+)
+
+var knownActionNames = map[string]acting.ActionName{
+	"f": acting.Fold, "fold": acting.Fold,
+	"ch": acting.Check, "check": acting.Check,
+	"b": acting.Bet, "bet": acting.Bet,
+	"c": acting.Call, "call": acting.Call,
+	"r": acting.Raise, "raise": acting.Raise,
+}
+
+type tokenKind int8
+
+const (
+	wordToken tokenKind = iota
+	stringToken
+	openToken
+	closeToken
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+type infoSetDefinition struct {
+	actions       []acting.Action
+	probabilities []float32
+	exact         []*big.Rat
+}
+
+type parser struct {
+	tokens   []token
+	position int
+	chance   map[int]*infoSetDefinition
+	players  map[InformationSet]*infoSetDefinition
+	outcomes map[int]*big.Rat
+	decimal  bool
+}
+
+// decimalTolerance - rounding allowed in sum of chance probabilities written as decimals (D number format)
+var decimalTolerance = big.NewRat(1, 1000000)
+
+// Import - builds a game tree from two-player zero-sum game in Gambit extensive form (.efg) format
+func Import(r io.Reader) (*Node, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := tokenize(string(data))
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, chance: map[int]*infoSetDefinition{},
+		players: map[InformationSet]*infoSetDefinition{}, outcomes: map[int]*big.Rat{}}
+	if err := p.header(); err != nil {
+		return nil, err
+	}
+	root, err := p.node(nil, new(big.Rat))
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, p.errorf("unexpected %q after game tree", p.peek().value)
+	}
+	return root, nil
+}
+
+func (p *parser) header() error {
+	for _, expected := range []string{"EFG", "2"} {
+		if word, err := p.word(); err != nil || word != expected {
+			return p.errorf("expected %q in header", expected)
+		}
+	}
+	format, err := p.word()
+	if err != nil || (format != "R" && format != "D") {
+		return p.errorf("expected number format R or D in header")
+	}
+	p.decimal = format == "D"
+	if _, err := p.text(); err != nil {
+		return err
+	}
+	if err := p.expect(openToken); err != nil {
+		return err
+	}
+	players := 0
+	for !p.done() && p.peek().kind == stringToken {
+		p.position++
+		players++
+	}
+	if err := p.expect(closeToken); err != nil {
+		return err
+	}
+	if players != 2 {
+		return p.errorf("only two-player games are supported, got %v players", players)
+	}
+	if !p.done() && p.peek().kind == stringToken {
+		p.position++
+	}
+	return nil
+}
+
+func (p *parser) node(parent *Node, payoff *big.Rat) (*Node, error) {
+	kind, err := p.word()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.text(); err != nil {
+		return nil, err
+	}
+	node := &Node{parent: parent}
+	switch kind {
+	case "c":
+		err = p.chanceNode(node)
+	case "p":
+		err = p.playerNode(node)
+	case "t":
+		node.kind = terminalNode
+		node.actor = actor{acting.ChanceId}
+	default:
+		err = p.errorf("unknown node type %q", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	outcome, err := p.outcome()
+	if err != nil {
+		return nil, err
+	}
+	payoff = new(big.Rat).Add(payoff, outcome)
+
+	if node.kind == terminalNode {
+		value, _ := payoff.Float32()
+		node.payoff = value
+		return node, nil
+	}
+	node.children = make([]*Node, len(node.actions))
+	for i := range node.actions {
+		if node.children[i], err = p.node(node, payoff); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+func (p *parser) chanceNode(node *Node) error {
+	number, err := p.integer()
+	if err != nil {
+		return err
+	}
+	definition, defined := p.chance[number]
+	p.optionalString()
+	if !p.done() && p.peek().kind == openToken {
+		p.position++
+		definition = &infoSetDefinition{}
+		for !p.done() && p.peek().kind != closeToken {
+			label, err := p.text()
+			if err != nil {
+				return err
+			}
+			probability, err := p.rational()
+			if err != nil {
+				return err
+			}
+			value, _ := probability.Float32()
+			definition.actions = append(definition.actions, Action{label: label, index: len(definition.actions)})
+			definition.probabilities = append(definition.probabilities, value)
+			definition.exact = append(definition.exact, probability)
+		}
+		if err := p.expect(closeToken); err != nil {
+			return err
+		}
+		total := new(big.Rat)
+		for _, probability := range definition.exact {
+			if probability.Sign() < 0 {
+				return p.errorf("chance information set %v has negative probability %v", number, probability.RatString())
+			}
+			total.Add(total, probability)
+		}
+		if !p.sumsToOne(total) {
+			return p.errorf("probabilities of chance information set %v sum to %v instead of 1", number, total.RatString())
+		}
+		p.chance[number] = definition
+	} else if !defined {
+		return p.errorf("chance information set %v has no actions", number)
+	}
+	node.kind = chanceNode
+	node.actor = actor{acting.ChanceId}
+	node.actions = definition.actions
+	node.probabilities = definition.probabilities
+	node.exact = definition.exact
+	return nil
+}
+
+func (p *parser) playerNode(node *Node) error {
+	player, err := p.integer()
+	if err != nil {
+		return err
+	}
+	if player != 1 && player != 2 {
+		return p.errorf("unknown player %v", player)
+	}
+	number, err := p.integer()
+	if err != nil {
+		return err
+	}
+	id := acting.PlayerA
+	if player == 2 {
+		id = acting.PlayerB
+	}
+	infoSet := InformationSet{Player: id, Number: number}
+	definition, defined := p.players[infoSet]
+	p.optionalString()
+	if !p.done() && p.peek().kind == openToken {
+		p.position++
+		labels := []string{}
+		for !p.done() && p.peek().kind != closeToken {
+			label, err := p.text()
+			if err != nil {
+				return err
+			}
+			labels = append(labels, label)
+		}
+		if err := p.expect(closeToken); err != nil {
+			return err
+		}
+		actions, err := playerActions(labels)
+		if err != nil {
+			return p.errorf("information set %v of player %v: %v", number, player, err)
+		}
+		if defined && !sameLabels(definition.actions, labels) {
+			return p.errorf("information set %v of player %v redefined with different actions", number, player)
+		}
+		if !defined {
+			definition = &infoSetDefinition{actions: actions}
+			p.players[infoSet] = definition
+		}
+	} else if !defined {
+		return p.errorf("information set %v of player %v has no actions", number, player)
+	}
+	node.kind = playerNode
+	node.actor = actor{id}
+	node.infoSet = infoSet
+	node.actions = definition.actions
+	return nil
+}
+
+// outcome - reads outcome reference (and its definition if present), returns payoff of player 1
+func (p *parser) outcome() (*big.Rat, error) {
+	number, err := p.integer()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() && p.peek().kind == stringToken {
+		p.position++
+		if err := p.expect(openToken); err != nil {
+			return nil, err
+		}
+		payoffs := []*big.Rat{}
+		for !p.done() && p.peek().kind != closeToken {
+			payoff, err := p.rational()
+			if err != nil {
+				return nil, err
+			}
+			payoffs = append(payoffs, payoff)
+		}
+		if err := p.expect(closeToken); err != nil {
+			return nil, err
+		}
+		if len(payoffs) != 2 {
+			return nil, p.errorf("outcome %v should have two payoffs", number)
+		}
+		if new(big.Rat).Add(payoffs[0], payoffs[1]).Sign() != 0 {
+			return nil, p.errorf("outcome %v is not zero-sum", number)
+		}
+		p.outcomes[number] = payoffs[0]
+	}
+	if number == 0 {
+		return new(big.Rat), nil
+	}
+	payoff, ok := p.outcomes[number]
+	if !ok {
+		return nil, p.errorf("outcome %v has no payoffs", number)
+	}
+	return payoff, nil
+}
+
+// playerActions - actions named after acting names when labels allow it, imported action names numbered by index otherwise
+func playerActions(labels []string) ([]acting.Action, error) {
+	actions := make([]acting.Action, len(labels))
+	named := map[acting.ActionName]bool{}
+	for i, label := range labels {
+		name, ok := knownActionNames[strings.ToLower(label)]
+		if !ok || named[name] {
+			named = nil
+			break
+		}
+		named[name] = true
+		actions[i] = Action{label: label, name: name, index: i}
+	}
+	if named != nil {
+		return actions, nil
+	}
+	if len(labels) > acting.MaxImportedActions {
+		return nil, fmt.Errorf("%v actions, at most %v are supported", len(labels), acting.MaxImportedActions)
+	}
+	for i, label := range labels {
+		actions[i] = Action{label: label, name: acting.ImportedActionName(i), index: i}
+	}
+	return actions, nil
+}
+
+// sameLabels - true when actions are labelled by labels in the same order
+func sameLabels(actions []acting.Action, labels []string) bool {
+	if len(actions) != len(labels) {
+		return false
+	}
+	for i, action := range actions {
+		if action.(Action).label != labels[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func tokenize(input string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(input); {
+		switch c := input[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '{':
+			tokens = append(tokens, token{openToken, "{"})
+			i++
+		case c == '}':
+			tokens = append(tokens, token{closeToken, "}"})
+			i++
+		case c == '"':
+			value := []byte{}
+			for i++; i < len(input) && input[i] != '"'; i++ {
+				if input[i] == '\\' && i+1 < len(input) {
+					i++
+				}
+				value = append(value, input[i])
+			}
+			if i == len(input) {
+				return nil, errors.New("efg: unterminated string")
+			}
+			tokens = append(tokens, token{stringToken, string(value)})
+			i++
+		default:
+			start := i
+			for i < len(input) && !strings.ContainsRune(" \t\n\r,{}\"", rune(input[i])) {
+				i++
+			}
+			tokens = append(tokens, token{wordToken, input[start:i]})
+		}
+	}
+	return tokens, nil
+}
+
+func (p *parser) done() bool {
+	return p.position >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.position]
+}
+
+func (p *parser) next(kind tokenKind, what string) (string, error) {
+	if p.done() {
+		return "", p.errorf("expected %v, got end of file", what)
+	}
+	t := p.peek()
+	if t.kind != kind {
+		return "", p.errorf("expected %v, got %q", what, t.value)
+	}
+	p.position++
+	return t.value, nil
+}
+
+func (p *parser) expect(kind tokenKind) error {
+	what := map[tokenKind]string{openToken: "{", closeToken: "}"}[kind]
+	_, err := p.next(kind, what)
+	return err
+}
+
+func (p *parser) word() (string, error) {
+	return p.next(wordToken, "word")
+}
+
+func (p *parser) text() (string, error) {
+	return p.next(stringToken, "string")
+}
+
+func (p *parser) optionalString() {
+	if !p.done() && p.peek().kind == stringToken {
+		p.position++
+	}
+}
+
+func (p *parser) integer() (int, error) {
+	word, err := p.word()
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.Atoi(word)
+	if err != nil {
+		return 0, p.errorf("expected integer, got %q", word)
+	}
+	return value, nil
+}
+
+func (p *parser) rational() (*big.Rat, error) {
+	word, err := p.word()
+	if err != nil {
+		return nil, err
+	}
+	value, ok := new(big.Rat).SetString(word)
+	if !ok {
+		return nil, p.errorf("expected number, got %q", word)
+	}
+	return value, nil
+}
+
+func (p *parser) sumsToOne(total *big.Rat) bool {
+	difference := new(big.Rat).Sub(total, big.NewRat(1, 1))
+	if p.decimal {
+		return new(big.Rat).Abs(difference).Cmp(decimalTolerance) <= 0
+	}
+	return difference.Sign() == 0
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("efg: token %v: %v", p.position, fmt.Sprintf(format, args...))
+}
@@ -1,6 +1,17 @@
 package games
 
-import "github.com/int8/go-counterfactual-regret-minimization/acting"
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"strings"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/rounds"
+	"github.com/int8/go-counterfactual-regret-minimization/table"
+)
 
 type InformationSet interface{}
 
@@ -13,3 +24,200 @@ type GameState interface {
 	CurrentActor() acting.Actor
 	Evaluate() float32
 }
+
+// MutableGameState - game state moved in place: Apply plays an action, Undo takes the last applied one back.
+// Traversal with Apply and Undo does not clone table, players and deck the way Act does.
+type MutableGameState interface {
+	GameState
+	Apply(action acting.Action)
+	Undo()
+}
+
+// Mutable - implemented by game states able to create a mutable copy of themselves
+type Mutable interface {
+	Mutable() MutableGameState
+}
+
+// PokerGameState - state of a poker game played at a table (kuhn and rhodeisland)
+type PokerGameState interface {
+	GameState
+	Table() *table.PokerTable
+	Round() rounds.PokerRound
+	PrivateCard(id acting.ActorID) *cards.Card
+}
+
+// ChanceDistribution - implemented by chance states whose actions are not equally likely
+type ChanceDistribution interface {
+	ChanceProbabilities() []float32
+}
+
+// ChanceProbabilities - probabilities of chance actions (as returned by state.Actions()), uniform unless state says otherwise
+func ChanceProbabilities(state GameState, actions []acting.Action) []float32 {
+	if distribution, ok := state.(ChanceDistribution); ok {
+		return distribution.ChanceProbabilities()
+	}
+	probabilities := make([]float32, len(actions))
+	for i := range actions {
+		probabilities[i] = 1. / float32(len(actions))
+	}
+	return probabilities
+}
+
+// ExactChanceDistribution - implemented by chance states knowing their probabilities as exact fractions (e.g. imported from .efg files)
+type ExactChanceDistribution interface {
+	ExactChanceProbabilities() []*big.Rat
+}
+
+// ExactChanceProbabilities - ChanceProbabilities as fractions, float probabilities are rounded to the fraction with the smallest
+// denominator converting back to the same float32 (1/3 for float32(1./3))
+func ExactChanceProbabilities(state GameState, actions []acting.Action) []*big.Rat {
+	probabilities := make([]*big.Rat, len(actions))
+	if distribution, ok := state.(ExactChanceDistribution); ok {
+		for i, probability := range distribution.ExactChanceProbabilities() {
+			probabilities[i] = new(big.Rat).Set(probability)
+		}
+		return probabilities
+	}
+	if distribution, ok := state.(ChanceDistribution); ok {
+		for i, probability := range distribution.ChanceProbabilities() {
+			probabilities[i] = roundFloat32(probability)
+		}
+		return probabilities
+	}
+	for i := range actions {
+		probabilities[i] = big.NewRat(1, int64(len(actions)))
+	}
+	return probabilities
+}
+
+// maxRoundedDenominator - bound on denominators tried by roundFloat32, every float32 in [0, 1] is exact with a larger one
+const maxRoundedDenominator = 1 << 24
+
+// roundFloat32 - first continued fraction convergent of value converting back to it, value itself when there is none
+func roundFloat32(value float32) *big.Rat {
+	if value < 0 || value > 1 {
+		return new(big.Rat).SetFloat64(float64(value))
+	}
+	var previousNum, num, previousDen, den int64 = 0, 1, 1, 0
+	for x := float64(value); ; {
+		term := math.Floor(x)
+		previousNum, num = num, int64(term)*num+previousNum
+		previousDen, den = den, int64(term)*den+previousDen
+		if den > maxRoundedDenominator {
+			return new(big.Rat).SetFloat64(float64(value))
+		}
+		if float32(float64(num)/float64(den)) == value || x == term {
+			return big.NewRat(num, den)
+		}
+		x = 1 / (x - term)
+	}
+}
+
+// SampleIndex - draws index according to probabilities (the last positive one when they sum up to less than the draw),
+// rng defaults to math/rand global source when nil
+func SampleIndex(rng *rand.Rand, probabilities []float32) int {
+	var r float32
+	if rng != nil {
+		r = rng.Float32()
+	} else {
+		r = rand.Float32()
+	}
+	last := len(probabilities) - 1
+	for i, probability := range probabilities {
+		if probability <= 0 {
+			continue
+		}
+		if r < probability {
+			return i
+		}
+		r -= probability
+		last = i
+	}
+	return last
+}
+
+// SampleChance - chance action of state drawn according to ChanceProbabilities
+func SampleChance(state GameState, rng *rand.Rand) acting.Action {
+	actions := state.Actions()
+	return actions[SampleIndex(rng, ChanceProbabilities(state, actions))]
+}
+
+// Abstraction - maps information sets of a game to (fewer) information sets strategies are computed for
+type Abstraction interface {
+	Abstract(informationSet InformationSet) InformationSet
+}
+
+// Expansion - abstraction able to list all information sets it maps to an abstract one
+type Expansion interface {
+	Abstraction
+	Expand(abstract InformationSet) []InformationSet
+}
+
+// InformationSetDescription - information set decoded by the game it comes from
+type InformationSetDescription struct {
+	PrivateCards []cards.Card
+	PublicCards  []cards.Card
+	// Rounds - player actions of every betting round so far, the last one is the current round
+	Rounds [][]acting.ActionName
+}
+
+// BetSizer - implemented by poker game states knowing how many chips current actor puts into the pot with an action
+type BetSizer interface {
+	BetAmount(action acting.Action) float32
+}
+
+// InformationSetDescriber - implemented by game states able to decode information sets of their game
+type InformationSetDescriber interface {
+	DescribeInformationSet(informationSet InformationSet) (InformationSetDescription, error)
+}
+
+// NewInformationSetDescription - description with history (in order of play, deals included) split into betting rounds
+func NewInformationSetDescription(privateCards []cards.Card, publicCards []cards.Card, history []acting.ActionName) InformationSetDescription {
+	description := InformationSetDescription{PrivateCards: privateCards, PublicCards: publicCards, Rounds: [][]acting.ActionName{}}
+	for _, name := range history {
+		switch name {
+		case acting.DealPrivateCards, acting.DealPublicCards:
+			description.Rounds = append(description.Rounds, []acting.ActionName{})
+		default:
+			if len(description.Rounds) == 0 {
+				description.Rounds = append(description.Rounds, []acting.ActionName{})
+			}
+			description.Rounds[len(description.Rounds)-1] = append(description.Rounds[len(description.Rounds)-1], name)
+		}
+	}
+	return description
+}
+
+// String - e.g. "♥K | ♠A | B C / Ch", public cards are "-" when none are dealt
+func (description InformationSetDescription) String() string {
+	rounds := []string{}
+	for _, round := range description.Rounds {
+		names := []string{}
+		for _, name := range round {
+			names = append(names, name.String())
+		}
+		rounds = append(rounds, strings.Join(names, " "))
+	}
+	return fmt.Sprintf("%v | %v | %v", cardsString(description.PrivateCards), cardsString(description.PublicCards), strings.Join(rounds, " / "))
+}
+
+// DescribeInformationSet - readable information set when game is a describer, raw value otherwise
+func DescribeInformationSet(game GameState, informationSet InformationSet) string {
+	if describer, ok := game.(InformationSetDescriber); ok {
+		if description, err := describer.DescribeInformationSet(informationSet); err == nil {
+			return description.String()
+		}
+	}
+	return fmt.Sprintf("%v", informationSet)
+}
+
+func cardsString(cardList []cards.Card) string {
+	if len(cardList) == 0 {
+		return "-"
+	}
+	texts := []string{}
+	for _, card := range cardList {
+		texts = append(texts, card.String())
+	}
+	return strings.Join(texts, " ")
+}
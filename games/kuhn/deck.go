@@ -22,6 +22,11 @@ func CreateKuhnDeck() *KuhnDeck {
 	return &deck
 }
 
+// CreateKuhnBitmaskDeck - Kuhn cards as cards.BitmaskDeck
+func CreateKuhnBitmaskDeck() *cards.BitmaskDeck {
+	return cards.BitmaskDeckOf(&cards.JackHearts, &cards.QueenHearts, &cards.KingHearts)
+}
+
 func (d *KuhnDeck) Shuffle() {
 	rand.Seed(time.Now().UTC().UnixNano())
 }
@@ -30,14 +35,21 @@ func (d *KuhnDeck) RemoveCard(card *cards.Card) {
 	delete(d.Cards, card)
 }
 
+func (d *KuhnDeck) AddCard(card *cards.Card) {
+	d.Cards[card] = true
+}
+
 func (d *KuhnDeck) CardsLeft() int {
 	return len(d.Cards)
 }
 
+// RemainingCards - cards in fixed order J Q K
 func (d *KuhnDeck) RemainingCards() []*cards.Card {
 	kuhncards := make([]*cards.Card, 0, len(d.Cards))
-	for card := range d.Cards {
-		kuhncards = append(kuhncards, card)
+	for _, card := range []*cards.Card{&cards.JackHearts, &cards.QueenHearts, &cards.KingHearts} {
+		if d.Cards[card] {
+			kuhncards = append(kuhncards, card)
+		}
 	}
 	return kuhncards
 }
@@ -1,10 +1,13 @@
 package kuhn
 
 import (
+	"encoding/gob"
 	"errors"
+	"fmt"
 	"github.com/int8/go-counterfactual-regret-minimization/acting"
 	"github.com/int8/go-counterfactual-regret-minimization/cards"
 	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/notation"
 	"github.com/int8/go-counterfactual-regret-minimization/rounds"
 	"github.com/int8/go-counterfactual-regret-minimization/table"
 )
@@ -14,6 +17,11 @@ const Ante float32 = 1.0
 const InformationSetSize = 24
 const InformationSetSizeBytes = 3
 
+func init() {
+	// private card and betting packed into 3 bytes, gob encoded as interface keys of cfr.StrategyMap
+	gob.Register([InformationSetSizeBytes]byte{})
+}
+
 // KuhnGameState - Kuhn Poker Game State
 type KuhnGameState struct {
 	round         rounds.PokerRound
@@ -23,6 +31,8 @@ type KuhnGameState struct {
 	actors        map[acting.ActorID]acting.Actor
 	nextToMove    acting.ActorID
 	terminal      bool
+	// undo - history of a mutable state (see Mutable), nil for immutable ones
+	undo *undoStack
 }
 
 func (state *KuhnGameState) Act(action acting.Action) games.GameState {
@@ -62,6 +72,38 @@ func (state *KuhnGameState) CurrentActor() acting.Actor {
 	return state.actors[state.nextToMove]
 }
 
+// Table - poker table of the state (shared with the state, do not modify)
+func (state *KuhnGameState) Table() *table.PokerTable {
+	return state.table
+}
+
+func (state *KuhnGameState) Round() rounds.PokerRound {
+	return state.round
+}
+
+// PrivateCard - private card of a player, nil before cards are dealt
+func (state *KuhnGameState) PrivateCard(id acting.ActorID) *cards.Card {
+	return state.playerActor(id).Card
+}
+
+// String - hand in notation of games/notation package, e.g. "Kh Qh | cr"
+func (state *KuhnGameState) String() string {
+	actions := []acting.Action{}
+	for s := state; s.parent != nil; s = s.parent {
+		actions = append([]acting.Action{s.causingAction}, actions...)
+	}
+	return notation.Format(state, actions)
+}
+
+// ParseState - state of Kuhn hand written in notation of games/notation package, played from root
+func ParseState(root *KuhnGameState, text string) (*KuhnGameState, error) {
+	state, err := notation.Replay(root, text)
+	if err != nil {
+		return nil, err
+	}
+	return state.(*KuhnGameState), nil
+}
+
 //TODO: test it carefully
 func (state *KuhnGameState) Evaluate() float32 {
 	currentActor := state.playerActor(state.CurrentActor().GetID())
@@ -109,49 +151,100 @@ func (state *KuhnGameState) InformationSet() games.InformationSet {
 	return games.InformationSet(informationSet)
 }
 
+// DescribeInformationSet - decodes information set of Kuhn Poker: private card and actions so far
+func (state *KuhnGameState) DescribeInformationSet(informationSet games.InformationSet) (games.InformationSetDescription, error) {
+	data, ok := informationSet.([InformationSetSizeBytes]byte)
+	if !ok {
+		return games.InformationSetDescription{}, fmt.Errorf("kuhn: %v is not an information set of Kuhn Poker", informationSet)
+	}
+	privateCard := cards.Card{Symbol: read4BitsFromByteArray(data, 0), Suit: read3BitsFromByteArray(data, 4)}
+	history := []acting.ActionName{}
+	for i := uint(7); i+3 <= InformationSetSize; i += 3 {
+		name := acting.PokerActionName(read3BitsFromByteArray(data, i))
+		if name == acting.NoAction {
+			break
+		}
+		// information set keeps the most recent action first
+		history = append([]acting.ActionName{name}, history...)
+	}
+	return games.NewInformationSetDescription([]cards.Card{privateCard}, nil, history), nil
+}
+
 func (state *KuhnGameState) stack(actor acting.ActorID) float32 {
 	return state.actors[actor].(*Player).Stack
 }
 
 func (state *KuhnGameState) actAsChance(action acting.Action) games.GameState {
-	var child *KuhnGameState
-	if action.Name() == acting.DealPrivateCards {
-		child = state.dealPrivateCards(action.(DealPrivateCardsAction).CardA, action.(DealPrivateCardsAction).CardB)
+	if action.Name() != acting.DealPrivateCards {
+		return nil
 	}
+	round, nextToMove, terminal := state.transition(action)
+	child := createChild(state, round, action, nextToMove, terminal)
+	// important to deal using child deck / not current chance deck
+	state.play(action, child)
 	return child
 }
 
 func (state *KuhnGameState) actAsPlayer(action acting.Action) games.GameState {
 
-	var child *KuhnGameState
-
 	if !actionInSlice(action, state.Actions()) {
 		panic("action not available")
 	}
-	actor := state.CurrentActor()
+	round, nextToMove, terminal := state.transition(action)
+	child := createChild(state, round, action, nextToMove, terminal)
+	state.play(action, child)
+	return child
+}
 
-	defer func() {
-		if action.Name() == acting.Call || action.Name() == acting.Bet {
-			child.playerActor(actor.GetID()).PlaceBet(child.table, BetSize)
-		}
-		if action.Name() == acting.Fold {
-			//opponent of folding player can now take his bet back
-			child.actors[actor.(*Player).Opponent()].(*Player).PlaceBet(child.table, -BetSize)
-		}
-	}()
+// transition - round, actor to move and terminal flag of the state reached with action
+func (state *KuhnGameState) transition(action acting.Action) (rounds.PokerRound, acting.ActorID, bool) {
+	if state.nextToMove == acting.ChanceId {
+		return state.round.NextRound(), acting.PlayerA, false
+	}
+	terminal := action.Name() == acting.Fold || action.Name() == acting.Call || (action.Name() == acting.Check && state.causingAction.Name() == acting.Check)
+	return state.round, -state.nextToMove, terminal
+}
 
-	if action.Name() == acting.Fold || action.Name() == acting.Call || (action.Name() == acting.Check && state.causingAction.Name() == acting.Check) {
-		child = createChild(state, state.round, action, state.CurrentActor().(*Player).Opponent(), true)
-		return child
+// play - effects of action taken in state (bets placed, cards dealt) on table, players and deck of target
+func (state *KuhnGameState) play(action acting.Action, target *KuhnGameState) {
+	switch action.Name() {
+	case acting.DealPrivateCards:
+		deal := action.(DealPrivateCardsAction)
+		target.playerActor(acting.PlayerA).PlaceBet(target.table, Ante)
+		target.playerActor(acting.PlayerB).PlaceBet(target.table, Ante)
+		target.playerActor(acting.PlayerA).CollectPrivateCard(deal.CardA)
+		target.playerActor(acting.PlayerB).CollectPrivateCard(deal.CardB)
+		target.actors[acting.ChanceId].(*Chance).deck.RemoveCard(deal.CardA)
+		target.actors[acting.ChanceId].(*Chance).deck.RemoveCard(deal.CardB)
+	case acting.Call, acting.Bet:
+		target.playerActor(state.nextToMove).PlaceBet(target.table, BetSize)
+	case acting.Fold:
+		//opponent of folding player can now take his bet back
+		target.playerActor(-state.nextToMove).PlaceBet(target.table, -BetSize)
 	}
+}
 
-	child = createChild(state, state.round, action, actor.(*Player).Opponent(), false)
-	return child
+
+// BetAmount - chips current player puts into the pot with action
+func (state *KuhnGameState) BetAmount(action acting.Action) float32 {
+	if action.Name() == acting.Bet || action.Name() == acting.Call {
+		return BetSize
+	}
+	return 0
 }
 
+// NewRoot - root of Kuhn poker with both players holding stack
+func NewRoot(stack float32) *KuhnGameState {
+	return Root(&Player{Id: acting.PlayerA, Stack: stack}, &Player{Id: acting.PlayerB, Stack: stack})
+}
 
 func Root(playerA *Player, playerB *Player) *KuhnGameState {
-	chance := &Chance{id: acting.ChanceId, deck: CreateKuhnDeck()}
+	return RootWithDeck(playerA, playerB, CreateKuhnDeck())
+}
+
+// RootWithDeck - root dealing from given deck of Kuhn cards (e.g. CreateKuhnBitmaskDeck)
+func RootWithDeck(playerA *Player, playerB *Player, deck cards.Deck) *KuhnGameState {
+	chance := &Chance{id: acting.ChanceId, deck: deck}
 
 	actors := map[acting.ActorID]acting.Actor{acting.PlayerA: playerA, acting.PlayerB: playerB, acting.ChanceId: chance}
 	pokerTable := &table.PokerTable{Pot: 0, Cards: []cards.Card{}}
@@ -161,26 +254,17 @@ func Root(playerA *Player, playerB *Player) *KuhnGameState {
 }
 
 func createChild(blueprint *KuhnGameState, round rounds.PokerRound, Action acting.Action, nextToMove acting.ActorID, terminal bool) *KuhnGameState {
+	parent := blueprint
+	if blueprint.undo != nil {
+		// mutable blueprint keeps moving, see Mutable
+		parent = blueprint.snapshot()
+	}
 	child := KuhnGameState{round: round,
-		parent: blueprint, causingAction: Action, terminal: terminal,
+		parent: parent, causingAction: Action, terminal: terminal,
 		table: blueprint.table.Clone(), actors: cloneActorsMap(blueprint.actors), nextToMove: nextToMove}
 	return &child
 }
 
-func (state *KuhnGameState) dealPrivateCards(cardA *cards.Card, cardB *cards.Card) *KuhnGameState {
-
-	child := createChild(state, state.round.NextRound(), DealPrivateCardsAction{cardA, cardB}, acting.PlayerA, false)
-	// important to deal using child deck / not current chance deck
-	child.actors[acting.PlayerA].(*Player).PlaceBet(child.table, Ante)
-	child.actors[acting.PlayerB].(*Player).PlaceBet(child.table, Ante)
-	child.actors[acting.PlayerA].(*Player).CollectPrivateCard(cardA)
-	child.actors[acting.PlayerB].(*Player).CollectPrivateCard(cardB)
-	child.actors[acting.ChanceId].(*Chance).deck.RemoveCard(cardA)
-	child.actors[acting.ChanceId].(*Chance).deck.RemoveCard(cardB)
-
-	return child
-}
-
 func (state *KuhnGameState) chanceActions(chance *Chance) []acting.Action {
 	if state.round == rounds.Start {
 		deckSize := int(chance.deck.CardsLeft())
@@ -430,3 +430,95 @@ func createInformationSet(card cards.Card, actions []acting.Action) [Information
 
 	return informationSet
 }
+
+func TestNotationRoundTrip(t *testing.T) {
+	root := createRootForTest(100., 100.)
+	for _, hand := range []string{"-", "Kh Qh |", "Kh Qh | c", "Kh Qh | crc", "Jh Qh | rf"} {
+		state, err := ParseState(root, hand)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state.String() != hand {
+			t.Errorf("Hand %v should be written back unchanged, got %v", hand, state.String())
+		}
+	}
+	for _, hand := range []string{"Kh Kh |", "Ks Qh |", "Kh Qh | ccc", "Kh Qh | c/", "Kh Qh | c Jh"} {
+		if _, err := ParseState(root, hand); err == nil {
+			t.Errorf("Hand %q should be rejected", hand)
+		}
+	}
+}
+
+func TestDescribeInformationSet(t *testing.T) {
+	root := createRootForTest(100., 100.)
+	state, err := ParseState(root, "Kh Qh | c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	description, err := root.DescribeInformationSet(state.InformationSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(description.PrivateCards) != 1 || description.PrivateCards[0] != cards.QueenHearts || len(description.PublicCards) != 0 {
+		t.Errorf("Player B should hold queen of hearts, got %+v", description)
+	}
+	if description.String() != "♥Q | - | Ch" {
+		t.Errorf("Description should be readable, got %v", description)
+	}
+	if _, err := root.DescribeInformationSet([12]byte{}); err == nil {
+		t.Error("Information sets of other games should be rejected")
+	}
+}
+
+func TestApplyAndUndoFollowAct(t *testing.T) {
+	root := createRootForTest(100., 100.)
+	mutable := root.Mutable().(*KuhnGameState)
+
+	if terminals := compareWithAct(t, root, mutable); terminals != 30 {
+		t.Errorf("Kuhn Poker has 30 terminal states, %v reached", terminals)
+	}
+	if mutable.String() != root.String() || mutable.actors[acting.ChanceId].(*Chance).deck.CardsLeft() != 3 || mutable.table.Pot != 0 {
+		t.Errorf("Undo should restore the root, got %v with pot %v", mutable, mutable.table.Pot)
+	}
+}
+
+func TestActOnMutableKeepsHistoryAfterUndo(t *testing.T) {
+	root := createRootForTest(100., 100.)
+	mutable := root.Mutable()
+	mutable.Apply(root.Actions()[0])
+	actions := mutable.Actions()
+	mutable.Apply(actions[0])
+	next := mutable.Actions()
+	child := mutable.Act(next[len(next)-1]).(*KuhnGameState)
+	informationSet, history := child.InformationSet(), child.String()
+
+	mutable.Undo()
+	mutable.Apply(actions[1])
+	if child.InformationSet() != informationSet || child.String() != history {
+		t.Errorf("Child should keep its history %v after Undo and Apply, got %v", history, child)
+	}
+}
+
+// compareWithAct - walks subtree of state with Act and mutable with Apply and Undo, returns number of terminal states
+func compareWithAct(t *testing.T, state *KuhnGameState, mutable *KuhnGameState) int {
+	if state.String() != mutable.String() || state.IsTerminal() != mutable.IsTerminal() || state.table.Pot != mutable.table.Pot ||
+		state.stack(acting.PlayerA) != mutable.stack(acting.PlayerA) || state.stack(acting.PlayerB) != mutable.stack(acting.PlayerB) {
+		t.Fatalf("Mutable state %v differs from %v", mutable, state)
+	}
+	if state.IsTerminal() {
+		if state.Evaluate() != mutable.Evaluate() {
+			t.Fatalf("Mutable state %v evaluates differently", mutable)
+		}
+		return 1
+	}
+	if state.CurrentActor().GetID() != acting.ChanceId && state.InformationSet() != mutable.InformationSet() {
+		t.Fatalf("Information sets of %v differ", state)
+	}
+	terminals := 0
+	for _, action := range state.Actions() {
+		mutable.Apply(action)
+		terminals += compareWithAct(t, state.Act(action).(*KuhnGameState), mutable)
+		mutable.Undo()
+	}
+	return terminals
+}
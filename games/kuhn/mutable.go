@@ -0,0 +1,74 @@
+package kuhn
+
+import (
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+)
+
+// undoStack - what Undo restores, parents of a mutable state are linked through their parent fields
+type undoStack struct {
+	frames []undoFrame
+}
+
+// undoFrame - table and players before an applied action
+type undoFrame struct {
+	pot          float32
+	publicCards  int
+	stacks       [2]float32
+	privateCards [2]*cards.Card
+}
+
+// Mutable - copy of the state to be moved with Apply and Undo, the state itself is left untouched. Parents of the copy
+// and of states created from it with Act are snapshots holding history only (no table or players), they are never reused.
+func (state *KuhnGameState) Mutable() games.MutableGameState {
+	mutable := *state
+	mutable.table = state.table.Clone()
+	mutable.actors = cloneActorsMap(state.actors)
+	mutable.undo = &undoStack{frames: make([]undoFrame, 0, 16)}
+	return &mutable
+}
+
+// Apply - moves mutable state to its child reached with action (legal one, it is not checked)
+func (state *KuhnGameState) Apply(action acting.Action) {
+	if state.undo == nil {
+		panic("kuhn: Apply needs a mutable state, see Mutable")
+	}
+	round, nextToMove, terminal := state.transition(action)
+	playerA, playerB := state.playerActor(acting.PlayerA), state.playerActor(acting.PlayerB)
+	state.undo.frames = append(state.undo.frames, undoFrame{pot: state.table.Pot, publicCards: len(state.table.Cards),
+		stacks: [2]float32{playerA.Stack, playerB.Stack}, privateCards: [2]*cards.Card{playerA.Card, playerB.Card}})
+	parent := state.snapshot()
+
+	state.play(action, state)
+	state.parent = parent
+	state.round, state.causingAction, state.nextToMove, state.terminal = round, action, nextToMove, terminal
+}
+
+// Undo - moves mutable state back to the parent of the last applied action
+func (state *KuhnGameState) Undo() {
+	if state.undo == nil || len(state.undo.frames) == 0 {
+		panic("kuhn: there is no action to undo")
+	}
+	last := len(state.undo.frames) - 1
+	frame, parent := state.undo.frames[last], state.parent
+	if deal, ok := state.causingAction.(DealPrivateCardsAction); ok {
+		deck := state.actors[acting.ChanceId].(*Chance).deck
+		deck.AddCard(deal.CardA)
+		deck.AddCard(deal.CardB)
+	}
+	state.table.Pot = frame.pot
+	state.table.Cards = state.table.Cards[:frame.publicCards]
+	playerA, playerB := state.playerActor(acting.PlayerA), state.playerActor(acting.PlayerB)
+	playerA.Stack, playerB.Stack = frame.stacks[0], frame.stacks[1]
+	playerA.Card, playerB.Card = frame.privateCards[0], frame.privateCards[1]
+
+	state.round, state.parent, state.causingAction, state.nextToMove, state.terminal =
+		parent.round, parent.parent, parent.causingAction, parent.nextToMove, parent.terminal
+	state.undo.frames = state.undo.frames[:last]
+}
+
+// snapshot - new state holding history of state only, parent of states derived from a mutable one as it keeps moving
+func (state *KuhnGameState) snapshot() *KuhnGameState {
+	return &KuhnGameState{round: state.round, parent: state.parent, causingAction: state.causingAction, nextToMove: state.nextToMove, terminal: state.terminal}
+}
@@ -25,3 +25,19 @@ func cloneActorsMap(srcActors map[acting.ActorID]acting.Actor) map[acting.ActorI
 	}
 	return actors
 }
+
+func read4BitsFromByteArray(data [InformationSetSizeBytes]byte, start uint) [4]bool {
+	result := [4]bool{}
+	for i := uint(0); i < 4; i++ {
+		result[i] = data[(start+i)/8]&(1<<((start+i)%8)) > 0
+	}
+	return result
+}
+
+func read3BitsFromByteArray(data [InformationSetSizeBytes]byte, start uint) [3]bool {
+	result := [3]bool{}
+	for i := uint(0); i < 3; i++ {
+		result[i] = data[(start+i)/8]&(1<<((start+i)%8)) > 0
+	}
+	return result
+}
@@ -0,0 +1,150 @@
+// Package notation - compact description of poker hands played by bundled games
+//
+// Hand is written as private cards of players A and B, '|', betting actions and public cards, e.g.
+//
+//	Kh Qh | rc/c Jh
+//
+// Betting uses f (fold), c (check or call) and r (bet or raise), '/' closes a betting round and
+// public cards follow the betting. Cards are a rank (2-9, T, J, Q, K, A) followed by a suit (s, h, d, c).
+// Root state (before private cards are dealt) is written as "-".
+package notation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/rounds"
+)
+
+// Root - notation of a state before private cards are dealt
+const Root = "-"
+
+// Format - notation of state reached from root by actions
+func Format(state games.PokerGameState, actions []acting.Action) string {
+	if len(actions) == 0 {
+		return Root
+	}
+	betting := ""
+	for _, action := range actions {
+		switch action.Name() {
+		case acting.DealPrivateCards:
+		case acting.DealPublicCards:
+			betting += "/"
+		default:
+			betting += string(ActionChar(action.Name()))
+		}
+	}
+	if !state.IsTerminal() && state.CurrentActor().GetID() == acting.ChanceId {
+		// betting round is over, public card is not dealt yet
+		betting += "/"
+	}
+	fields := []string{cards.FormatCard(*state.PrivateCard(acting.PlayerA)), cards.FormatCard(*state.PrivateCard(acting.PlayerB)), "|"}
+	if betting != "" {
+		fields = append(fields, betting)
+	}
+	for _, card := range state.Table().Cards {
+		fields = append(fields, cards.FormatCard(card))
+	}
+	return strings.Join(fields, " ")
+}
+
+// Replay - state described by text, played from root
+func Replay(root games.PokerGameState, text string) (games.PokerGameState, error) {
+	text = strings.TrimSpace(text)
+	if text == Root {
+		return root, nil
+	}
+	halves := strings.Split(text, "|")
+	if len(halves) != 2 {
+		return nil, fmt.Errorf("notation: %q should contain single '|'", text)
+	}
+	privateFields := strings.Fields(halves[0])
+	if len(privateFields) != 2 {
+		return nil, fmt.Errorf("notation: %q should start with two private cards", text)
+	}
+	privateCards := [2]cards.Card{}
+	for i, field := range privateFields {
+		card, err := cards.ParseCard(field)
+		if err != nil {
+			return nil, err
+		}
+		privateCards[i] = card
+	}
+	betting := ""
+	board := []cards.Card{}
+	for i, field := range strings.Fields(halves[1]) {
+		if i == 0 && strings.Trim(field, "fcr/") == "" {
+			betting = field
+			continue
+		}
+		card, err := cards.ParseCard(field)
+		if err != nil {
+			return nil, err
+		}
+		board = append(board, card)
+	}
+
+	if root.Round() != rounds.Start {
+		return nil, errors.New("notation: hands are replayed from root")
+	}
+	state := games.DealPrivateCards(root, func(cardA cards.Card, cardB cards.Card) bool {
+		return cardA == privateCards[0] && cardB == privateCards[1]
+	})
+	if state == nil {
+		return nil, fmt.Errorf("notation: %v %v can not be dealt", cards.FormatCard(privateCards[0]), cards.FormatCard(privateCards[1]))
+	}
+	for i := 0; i < len(betting); i++ {
+		if state.IsTerminal() {
+			return nil, fmt.Errorf("notation: hand %q is over before its last action", text)
+		}
+		chance := state.CurrentActor().GetID() == acting.ChanceId
+		if betting[i] != '/' {
+			if chance {
+				return nil, fmt.Errorf("notation: betting round of %q is over, '/' expected", text)
+			}
+			action := FindAction(state, betting[i])
+			if action == nil {
+				return nil, fmt.Errorf("notation: action %q is not available", betting[i])
+			}
+			state = state.Act(action).(games.PokerGameState)
+			continue
+		}
+		if !chance {
+			return nil, fmt.Errorf("notation: betting round of %q is not over", text)
+		}
+		if len(board) == 0 {
+			if i != len(betting)-1 {
+				return nil, fmt.Errorf("notation: public card of %q is missing", text)
+			}
+			break
+		}
+		if state = games.DealPublicCard(state, board[0]); state == nil {
+			return nil, fmt.Errorf("notation: public card %v can not be dealt", cards.FormatCard(board[0]))
+		}
+		board = board[1:]
+	}
+	if len(board) > 0 {
+		return nil, fmt.Errorf("notation: too many public cards in %q", text)
+	}
+	return state, nil
+}
+
+// ActionChar - f (fold), c (check or call) or r (bet or raise)
+func ActionChar(name acting.ActionName) byte {
+	switch name {
+	case acting.Fold:
+		return 'f'
+	case acting.Bet, acting.Raise:
+		return 'r'
+	}
+	return 'c'
+}
+
+// FindAction - action of state written as char, nil when it is not available
+func FindAction(state games.GameState, char byte) acting.Action {
+	return games.FindAction(state, func(name acting.ActionName) bool { return ActionChar(name) == char })
+}
@@ -0,0 +1,16 @@
+package notation
+
+import (
+	"testing"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+)
+
+func TestActionChars(t *testing.T) {
+	chars := map[acting.ActionName]byte{acting.Fold: 'f', acting.Check: 'c', acting.Call: 'c', acting.Bet: 'r', acting.Raise: 'r'}
+	for name, char := range chars {
+		if ActionChar(name) != char {
+			t.Errorf("%v should be written as %c, got %c", name, char, ActionChar(name))
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package games
+
+import (
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+)
+
+// FindAction - first action of state whose name matches, nil when there is none
+func FindAction(state GameState, matches func(name acting.ActionName) bool) acting.Action {
+	for _, action := range state.Actions() {
+		if matches(action.Name()) {
+			return action
+		}
+	}
+	return nil
+}
+
+// DealPrivateCards - child of state dealing private cards accepted by matches (cards of player A and B), nil when there is none
+func DealPrivateCards(state PokerGameState, matches func(cardA cards.Card, cardB cards.Card) bool) PokerGameState {
+	for _, action := range state.Actions() {
+		child := state.Act(action).(PokerGameState)
+		if matches(*child.PrivateCard(acting.PlayerA), *child.PrivateCard(acting.PlayerB)) {
+			return child
+		}
+	}
+	return nil
+}
+
+// DealPublicCard - child of state dealing card to the table, nil when it can not be dealt
+func DealPublicCard(state PokerGameState, card cards.Card) PokerGameState {
+	for _, action := range state.Actions() {
+		child := state.Act(action).(PokerGameState)
+		if child.Table().Cards[len(child.Table().Cards)-1] == card {
+			return child
+		}
+	}
+	return nil
+}
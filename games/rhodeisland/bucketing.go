@@ -0,0 +1,329 @@
+package rhodeisland
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/table"
+)
+
+// Feature - hand strength measure clustered into buckets
+type Feature int8
+
+const (
+	// ExpectedHandStrength - probability of winning the showdown (ties count half) against random opponent card, averaged over cards to come
+	ExpectedHandStrength Feature = iota
+	// ExpectedHandStrengthSquared - average of squared showdown hand strength, rewards hands with potential to improve
+	ExpectedHandStrengthSquared
+)
+
+// Hand - private card and public cards dealt so far (cards.NoCard when not dealt)
+type Hand struct {
+	Private cards.Card
+	Flop    cards.Card
+	Turn    cards.Card
+}
+
+// BucketInformationSet - abstract information set: buckets of the hand in every round reached (-1 otherwise or when forgotten) and betting
+type BucketInformationSet struct {
+	Buckets [3]int8
+	// Betting - raw information set with cards cleared
+	Betting [InformationSetSizeBytes]byte
+}
+
+func init() {
+	// abstract strategies are keyed by bucket information sets instead of raw 12 byte arrays
+	gob.Register(BucketInformationSet{})
+}
+
+// cardBits - private, flop and turn cards take first 21 bits of information set
+const cardBits = 21
+
+// CardAbstraction - lossy abstraction of Rhode Island hands, hands of similar strength share bucket of their round
+// (bucket 0 is the weakest one). Implements games.Abstraction.
+type CardAbstraction struct {
+	// Buckets - number of buckets of preflop, flop and turn
+	Buckets [3]int
+	Feature Feature
+	// ImperfectRecall - abstract information sets keep bucket of the current round only, earlier ones are forgotten
+	ImperfectRecall bool
+	// Assignments - bucket of every hand of the deck
+	Assignments map[Hand]int8
+}
+
+// CreateCardAbstraction - clusters hand strengths of all hands dealt from deck into given number of buckets per round (k-means)
+func CreateCardAbstraction(deck cards.Deck, buckets [3]int, feature Feature) (*CardAbstraction, error) {
+	for _, k := range buckets {
+		if k < 1 || k > 127 {
+			return nil, fmt.Errorf("rhodeisland: number of buckets has to be within 1-127, got %v", buckets)
+		}
+	}
+	deckCards := sortedCards(deck)
+	if len(deckCards) < 4 {
+		return nil, fmt.Errorf("rhodeisland: deck of %v cards is too small", len(deckCards))
+	}
+	values := handValues(deckCards, feature)
+
+	abstraction := &CardAbstraction{Buckets: buckets, Feature: feature, Assignments: map[Hand]int8{}}
+	for round := range values {
+		hands := make([]Hand, 0, len(values[round]))
+		for hand := range values[round] {
+			hands = append(hands, hand)
+		}
+		sort.Slice(hands, func(i, j int) bool { return hands[i].less(hands[j]) })
+		points := make([]float64, len(hands))
+		for i, hand := range hands {
+			points[i] = values[round][hand]
+		}
+		for i, bucket := range kMeans(points, buckets[round]) {
+			abstraction.Assignments[hands[i]] = int8(bucket)
+		}
+	}
+	return abstraction, nil
+}
+
+// Bind - checks abstraction assigns buckets to exactly the hands dealt from deck, abstraction loaded from a file has to be
+// bound to the deck of the game before training (Abstract panics on hands it does not cover)
+func (abstraction *CardAbstraction) Bind(deck cards.Deck) error {
+	deckCards := sortedCards(deck)
+	hands := []Hand{}
+	for _, private := range deckCards {
+		hands = append(hands, Hand{private, cards.NoCard, cards.NoCard})
+		for _, flop := range deckCards {
+			if flop == private {
+				continue
+			}
+			hands = append(hands, Hand{private, flop, cards.NoCard})
+			for _, turn := range deckCards {
+				if turn != private && turn != flop {
+					hands = append(hands, Hand{private, flop, turn})
+				}
+			}
+		}
+	}
+	for _, hand := range hands {
+		if _, ok := abstraction.Assignments[hand]; !ok {
+			return fmt.Errorf("rhodeisland: hand %v %v %v is not covered by card abstraction, it was created for another deck", hand.Private, hand.Flop, hand.Turn)
+		}
+	}
+	if len(hands) != len(abstraction.Assignments) {
+		return fmt.Errorf("rhodeisland: card abstraction of %v hands was created for another deck of %v hands", len(abstraction.Assignments), len(hands))
+	}
+	return nil
+}
+
+// Bucket - bucket of the hand in its round
+func (abstraction *CardAbstraction) Bucket(hand Hand) int8 {
+	bucket, ok := abstraction.Assignments[hand]
+	if !ok {
+		panic(fmt.Errorf("rhodeisland: hand %v %v %v is not covered by card abstraction, see Bind", hand.Private, hand.Flop, hand.Turn))
+	}
+	return bucket
+}
+
+func (abstraction *CardAbstraction) Abstract(informationSet games.InformationSet) games.InformationSet {
+	data := informationSet.([InformationSetSizeBytes]byte)
+	hand := Hand{
+		Private: cards.Card{Symbol: read4BitsFromByteArray(data, 0), Suit: read3BitsFromByteArray(data, 4)},
+		Flop:    cards.Card{Symbol: read4BitsFromByteArray(data, 7), Suit: read3BitsFromByteArray(data, 11)},
+		Turn:    cards.Card{Symbol: read4BitsFromByteArray(data, 14), Suit: read3BitsFromByteArray(data, 18)},
+	}
+	abstract := BucketInformationSet{Buckets: [3]int8{-1, -1, -1}, Betting: data}
+	for i := uint(0); i < cardBits; i++ {
+		abstract.Betting[i/8] &^= 1 << (i % 8)
+	}
+	abstract.Buckets[0] = abstraction.Bucket(Hand{hand.Private, cards.NoCard, cards.NoCard})
+	if hand.Flop != cards.NoCard {
+		abstract.Buckets[1] = abstraction.Bucket(Hand{hand.Private, hand.Flop, cards.NoCard})
+	}
+	if hand.Turn != cards.NoCard {
+		abstract.Buckets[2] = abstraction.Bucket(hand)
+	}
+	if abstraction.ImperfectRecall {
+		for round := len(abstract.Buckets) - 1; round > 0; round-- {
+			if abstract.Buckets[round] >= 0 {
+				for earlier := 0; earlier < round; earlier++ {
+					abstract.Buckets[earlier] = -1
+				}
+				break
+			}
+		}
+	}
+	return abstract
+}
+
+// Save - writes abstraction with encoding/gob
+func (abstraction *CardAbstraction) Save(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(abstraction)
+}
+
+// LoadCardAbstraction - reads abstraction written by Save
+func LoadCardAbstraction(r io.Reader) (*CardAbstraction, error) {
+	abstraction := &CardAbstraction{}
+	if err := gob.NewDecoder(r).Decode(abstraction); err != nil {
+		return nil, fmt.Errorf("rhodeisland: reading card abstraction: %v", err)
+	}
+	return abstraction, nil
+}
+
+// handValues - feature value of every hand of every round
+func handValues(deckCards []cards.Card, feature Feature) [3]map[Hand]float64 {
+	values := [3]map[Hand]float64{{}, {}, {}}
+	flopCounts := map[Hand]int{}
+	preflopCounts := map[Hand]int{}
+	for _, private := range deckCards {
+		for _, flop := range deckCards {
+			for _, turn := range deckCards {
+				if private == flop || private == turn || flop == turn {
+					continue
+				}
+				strength := handStrength(private, flop, turn, deckCards)
+				if feature == ExpectedHandStrengthSquared {
+					strength *= strength
+				}
+				values[2][Hand{private, flop, turn}] = strength
+				flopHand, preflopHand := Hand{private, flop, cards.NoCard}, Hand{private, cards.NoCard, cards.NoCard}
+				values[1][flopHand] += strength
+				flopCounts[flopHand]++
+				values[0][preflopHand] += strength
+				preflopCounts[preflopHand]++
+			}
+		}
+	}
+	for hand, count := range flopCounts {
+		values[1][hand] /= float64(count)
+	}
+	for hand, count := range preflopCounts {
+		values[0][hand] /= float64(count)
+	}
+	return values
+}
+
+// handStrength - share of showdowns won against all opponent cards (ties count half)
+func handStrength(private cards.Card, flop cards.Card, turn cards.Card, deckCards []cards.Card) float64 {
+	board := &table.PokerTable{Cards: []cards.Card{flop, turn}}
+	player := &Player{Card: &private}
+	ownHand := player.EvaluateHand(board)
+	wins, showdowns := 0., 0.
+	for i := range deckCards {
+		opponentCard := deckCards[i]
+		if opponentCard == private || opponentCard == flop || opponentCard == turn {
+			continue
+		}
+		opponent := &Player{Card: &opponentCard}
+		showdowns++
+		wins += compareHands(ownHand, opponent.EvaluateHand(board))
+	}
+	return wins / showdowns
+}
+
+// compareHands - 1 when first hand wins, 0.5 for a tie, 0 otherwise
+func compareHands(hand []int8, other []int8) float64 {
+	for i := range hand {
+		if hand[i] > other[i] {
+			return 1
+		}
+		if hand[i] < other[i] {
+			return 0
+		}
+	}
+	return 0.5
+}
+
+// kMeans - one dimensional k-means, clusters are numbered from the lowest center
+func kMeans(points []float64, k int) []int {
+	sorted := append([]float64{}, points...)
+	sort.Float64s(sorted)
+	// quantiles are deterministic initial centers
+	centers := make([]float64, k)
+	for i := range centers {
+		centers[i] = sorted[(2*i+1)*len(sorted)/(2*k)]
+	}
+	assignments := make([]int, len(points))
+	for iteration := 0; iteration < 100; iteration++ {
+		changed := false
+		for i, point := range points {
+			nearest := 0
+			for j := range centers {
+				if abs64(point-centers[j]) < abs64(point-centers[nearest]) {
+					nearest = j
+				}
+			}
+			if assignments[i] != nearest || iteration == 0 {
+				changed = changed || assignments[i] != nearest
+				assignments[i] = nearest
+			}
+		}
+		sums, counts := make([]float64, k), make([]int, k)
+		for i, point := range points {
+			sums[assignments[i]] += point
+			counts[assignments[i]]++
+		}
+		for j := range centers {
+			if counts[j] > 0 {
+				centers[j] = sums[j] / float64(counts[j])
+			}
+		}
+		if !changed && iteration > 0 {
+			break
+		}
+	}
+
+	// renumber clusters by their centers, empty clusters are dropped
+	used := []int{}
+	for j := range centers {
+		for _, assignment := range assignments {
+			if assignment == j {
+				used = append(used, j)
+				break
+			}
+		}
+	}
+	sort.Slice(used, func(a, b int) bool { return centers[used[a]] < centers[used[b]] })
+	renumbered := make([]int, k)
+	for number, j := range used {
+		renumbered[j] = number
+	}
+	for i := range assignments {
+		assignments[i] = renumbered[assignments[i]]
+	}
+	return assignments
+}
+
+func sortedCards(deck cards.Deck) []cards.Card {
+	deckCards := []cards.Card{}
+	for _, card := range deck.RemainingCards() {
+		deckCards = append(deckCards, *card)
+	}
+	sort.Slice(deckCards, func(i, j int) bool { return cardLess(deckCards[i], deckCards[j]) })
+	return deckCards
+}
+
+func (hand Hand) less(other Hand) bool {
+	if hand.Private != other.Private {
+		return cardLess(hand.Private, other.Private)
+	}
+	if hand.Flop != other.Flop {
+		return cardLess(hand.Flop, other.Flop)
+	}
+	return cardLess(hand.Turn, other.Turn)
+}
+
+func cardLess(card cards.Card, other cards.Card) bool {
+	if card.Symbol != other.Symbol {
+		return cards.CardSymbol2Int(card.Symbol) < cards.CardSymbol2Int(other.Symbol)
+	}
+	return cards.CardSymbol2Int(cards.CardSymbol{card.Suit[0], card.Suit[1], card.Suit[2]}) <
+		cards.CardSymbol2Int(cards.CardSymbol{other.Suit[0], other.Suit[1], other.Suit[2]})
+}
+
+func abs64(value float64) float64 {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
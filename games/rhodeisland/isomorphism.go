@@ -0,0 +1,82 @@
+package rhodeisland
+
+import (
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+)
+
+// suit bits of private, flop and turn card in information set
+var suitOffsets = [3]uint{4, 11, 18}
+
+var suitsInOrder = [4]cards.CardSuit{cards.Hearts, cards.Diamonds, cards.Spades, cards.Clubs}
+
+// SuitIsomorphism - lossless abstraction of Rhode Island information sets, suits are renamed in order of appearance
+// (private card is always a heart, first other suit is a diamond and so on). Hand values depend on suits only through flushes,
+// so information sets differing by suit names only are strategically identical. Implements games.Expansion.
+type SuitIsomorphism struct{}
+
+func (SuitIsomorphism) Abstract(informationSet games.InformationSet) games.InformationSet {
+	data := informationSet.([InformationSetSizeBytes]byte)
+	renamed := map[cards.CardSuit]cards.CardSuit{cards.NoCardSuit: cards.NoCardSuit}
+	for _, offset := range suitOffsets {
+		suit := cards.CardSuit(read3BitsFromByteArray(data, offset))
+		if _, ok := renamed[suit]; !ok {
+			renamed[suit] = suitsInOrder[len(renamed)-1]
+		}
+	}
+	return renameSuits(data, renamed)
+}
+
+// Expand - all information sets with the same suit pattern as abstract one
+func (isomorphism SuitIsomorphism) Expand(abstract games.InformationSet) []games.InformationSet {
+	data := abstract.([InformationSetSizeBytes]byte)
+	seen := map[games.InformationSet]bool{}
+	informationSets := []games.InformationSet{}
+	for _, permutation := range suitPermutations() {
+		renamed := map[cards.CardSuit]cards.CardSuit{cards.NoCardSuit: cards.NoCardSuit}
+		for i, suit := range suitsInOrder {
+			renamed[suit] = permutation[i]
+		}
+		informationSet := renameSuits(data, renamed)
+		if !seen[informationSet] {
+			seen[informationSet] = true
+			informationSets = append(informationSets, informationSet)
+		}
+	}
+	return informationSets
+}
+
+func renameSuits(data [InformationSetSizeBytes]byte, renamed map[cards.CardSuit]cards.CardSuit) games.InformationSet {
+	result := data
+	for _, offset := range suitOffsets {
+		suit := renamed[cards.CardSuit(read3BitsFromByteArray(data, offset))]
+		for i := uint(0); i < 3; i++ {
+			bit := byte(1) << ((offset + i) % 8)
+			result[(offset+i)/8] &^= bit
+			if suit[i] {
+				result[(offset+i)/8] |= bit
+			}
+		}
+	}
+	return games.InformationSet(result)
+}
+
+// suitPermutations - all 24 orderings of four suits
+func suitPermutations() [][4]cards.CardSuit {
+	permutations := [][4]cards.CardSuit{}
+	var permute func(prefix []cards.CardSuit, left []cards.CardSuit)
+	permute = func(prefix []cards.CardSuit, left []cards.CardSuit) {
+		if len(left) == 0 {
+			permutation := [4]cards.CardSuit{}
+			copy(permutation[:], prefix)
+			permutations = append(permutations, permutation)
+			return
+		}
+		for i := range left {
+			rest := append(append([]cards.CardSuit{}, left[:i]...), left[i+1:]...)
+			permute(append(prefix, left[i]), rest)
+		}
+	}
+	permute([]cards.CardSuit{}, suitsInOrder[:])
+	return permutations
+}
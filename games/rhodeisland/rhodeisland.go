@@ -1,10 +1,13 @@
 package rhodeisland
 
 import (
+	"encoding/gob"
 	"errors"
+	"fmt"
 	"github.com/int8/go-counterfactual-regret-minimization/acting"
 	"github.com/int8/go-counterfactual-regret-minimization/cards"
 	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/notation"
 	"github.com/int8/go-counterfactual-regret-minimization/rounds"
 	"github.com/int8/go-counterfactual-regret-minimization/table"
 )
@@ -15,6 +18,11 @@ const PostFlopBetSize float32 = 20.
 const InformationSetSize = 8 * 12
 const InformationSetSizeBytes = 12
 
+func init() {
+	// private card, public cards and betting of all rounds packed into 12 bytes, gob needs the array type to decode strategy files
+	gob.Register([InformationSetSizeBytes]byte{})
+}
+
 var MaxRaises = 3
 
 const Ante float32 = 5.0
@@ -28,6 +36,8 @@ type RIGameState struct {
 	actors        map[acting.ActorID]acting.Actor
 	nextToMove    acting.ActorID
 	terminal      bool
+	// undo - history of a mutable state (see Mutable), nil for immutable ones
+	undo *undoStack
 }
 
 func (state *RIGameState) Act(action acting.Action) games.GameState {
@@ -67,6 +77,38 @@ func (state *RIGameState) CurrentActor() acting.Actor {
 	return state.actors[state.nextToMove]
 }
 
+// Table - poker table of the state (shared with the state, do not modify)
+func (state *RIGameState) Table() *table.PokerTable {
+	return state.table
+}
+
+func (state *RIGameState) Round() rounds.PokerRound {
+	return state.round
+}
+
+// PrivateCard - private card of a player, nil before cards are dealt
+func (state *RIGameState) PrivateCard(id acting.ActorID) *cards.Card {
+	return state.playerActor(id).Card
+}
+
+// String - hand in notation of games/notation package, e.g. "Kh Qh | rc/c Jh"
+func (state *RIGameState) String() string {
+	actions := []acting.Action{}
+	for s := state; s.parent != nil; s = s.parent {
+		actions = append([]acting.Action{s.causingAction}, actions...)
+	}
+	return notation.Format(state, actions)
+}
+
+// ParseState - state of Rhode Island hand written in notation of games/notation package, played from root
+func ParseState(root *RIGameState, text string) (*RIGameState, error) {
+	state, err := notation.Replay(root, text)
+	if err != nil {
+		return nil, err
+	}
+	return state.(*RIGameState), nil
+}
+
 func (state *RIGameState) Evaluate() float32 {
 	actor := state.playerActor(state.CurrentActor().GetID())
 	opponent := state.playerActor(-state.CurrentActor().GetID())
@@ -139,57 +181,104 @@ func (state *RIGameState) InformationSet() games.InformationSet {
 	return games.InformationSet(informationSet)
 }
 
+// DescribeInformationSet - decodes information set of Rhode Island Poker: private card, public cards and actions so far
+func (state *RIGameState) DescribeInformationSet(informationSet games.InformationSet) (games.InformationSetDescription, error) {
+	data, ok := informationSet.([InformationSetSizeBytes]byte)
+	if !ok {
+		return games.InformationSetDescription{}, fmt.Errorf("rhodeisland: %v is not an information set of Rhode Island Poker", informationSet)
+	}
+	privateCard := cards.Card{Symbol: read4BitsFromByteArray(data, 0), Suit: read3BitsFromByteArray(data, 4)}
+	publicCards := []cards.Card{}
+	for _, start := range []uint{7, 14} {
+		card := cards.Card{Symbol: read4BitsFromByteArray(data, start), Suit: read3BitsFromByteArray(data, start+4)}
+		if card != cards.NoCard {
+			publicCards = append(publicCards, card)
+		}
+	}
+	history := []acting.ActionName{}
+	for i := uint(21); i+3 <= InformationSetSize; i += 3 {
+		name := acting.PokerActionName(read3BitsFromByteArray(data, i))
+		if name == acting.NoAction {
+			break
+		}
+		// information set keeps the most recent action first
+		history = append([]acting.ActionName{name}, history...)
+	}
+	return games.NewInformationSetDescription([]cards.Card{privateCard}, publicCards, history), nil
+}
+
 func (state *RIGameState) stack(id acting.ActorID) float32 {
 	return state.actors[id].(*Player).Stack
 }
 
 func (state *RIGameState) actAsChance(action acting.Action) games.GameState {
-	var c *RIGameState
-	if action.Name() == acting.DealPublicCards {
-		c = state.dealPublicCard(action.(DealPublicCardAction).Card)
-	}
-
-	if action.Name() == acting.DealPrivateCards {
-		c = state.dealPrivateCards(action.(DealPrivateCardsAction).CardA, action.(DealPrivateCardsAction).CardB)
-	}
+	round, nextToMove, terminal := state.transition(action)
+	c := createChild(state, round, action, nextToMove, terminal)
+	// important to deal using child deck / not current chance deck
+	state.play(action, c)
 	return c
 }
 
 func (state *RIGameState) actAsPlayer(action acting.Action) games.GameState {
 
-	var c *RIGameState
-
 	if !actionInSlice(action, state.Actions()) {
 		panic("action not available")
 	}
-	actor := state.playerActor(state.nextToMove)
-	betSize := state.betSize()
-
-	defer func() {
-		if action.Name() == acting.Call || action.Name() == acting.Bet {
-			c.playerActor(actor.GetID()).PlaceBet(c.table, betSize)
-		}
-		if action.Name() == acting.Raise {
-			c.playerActor(actor.GetID()).PlaceBet(c.table, 2*betSize)
-		}
-		if action.Name() == acting.Fold {
-			c.playerActor(-actor.GetID()).PlaceBet(c.table, -betSize)
-		}
-	}()
+	round, nextToMove, terminal := state.transition(action)
+	c := createChild(state, round, action, nextToMove, terminal)
+	state.play(action, c)
+	return c
+}
 
+// transition - round, actor to move and terminal flag of the state reached with action
+func (state *RIGameState) transition(action acting.Action) (rounds.PokerRound, acting.ActorID, bool) {
+	if state.nextToMove == acting.ChanceId {
+		return state.round.NextRound(), acting.PlayerA, false
+	}
+	opponent := -state.nextToMove
 	if action.Name() == acting.Fold || (state.round == rounds.Turn && (action.Name() == acting.Call || (action.Name() == acting.Check && state.causingAction.Name() == acting.Check))) {
-		c = createChild(state, state.round, action, actor.Opponent(), true)
-		return c
+		return state.round, opponent, true
 	}
-
 	if action.Name() == acting.Call || (action.Name() == acting.Check && state.causingAction.Name() == acting.Check) {
-		c = createChild(state, state.round, action, acting.ChanceId, false)
-		return c
+		return state.round, acting.ChanceId, false
 	}
+	return state.round, opponent, false
+}
 
-	c = createChild(state, state.round, action, actor.Opponent(), false)
-	return c
+// play - effects of action taken in state (bets placed, cards dealt) on table, players and deck of target
+func (state *RIGameState) play(action acting.Action, target *RIGameState) {
+	betSize := state.betSize()
+	switch action.Name() {
+	case acting.DealPublicCards:
+		card := action.(DealPublicCardAction).Card
+		target.table.DropPublicCard(card)
+		target.chanceActor().deck.RemoveCard(card)
+	case acting.DealPrivateCards:
+		deal := action.(DealPrivateCardsAction)
+		target.playerActor(acting.PlayerA).PlaceBet(target.table, Ante)
+		target.playerActor(acting.PlayerB).PlaceBet(target.table, Ante)
+		target.playerActor(acting.PlayerA).CollectPrivateCard(deal.CardA)
+		target.playerActor(acting.PlayerB).CollectPrivateCard(deal.CardB)
+		target.chanceActor().deck.RemoveCard(deal.CardA)
+		target.chanceActor().deck.RemoveCard(deal.CardB)
+	case acting.Call, acting.Bet:
+		target.playerActor(state.nextToMove).PlaceBet(target.table, betSize)
+	case acting.Raise:
+		target.playerActor(state.nextToMove).PlaceBet(target.table, 2*betSize)
+	case acting.Fold:
+		target.playerActor(-state.nextToMove).PlaceBet(target.table, -betSize)
+	}
+}
 
+// BetAmount - chips current player puts into the pot with action (raise calls the bet first)
+func (state *RIGameState) BetAmount(action acting.Action) float32 {
+	switch action.Name() {
+	case acting.Bet, acting.Call:
+		return state.betSize()
+	case acting.Raise:
+		return 2 * state.betSize()
+	}
+	return 0
 }
 
 func (state *RIGameState) betSize() float32 {
@@ -199,6 +288,11 @@ func (state *RIGameState) betSize() float32 {
 	return PostFlopBetSize
 }
 
+// NewRoot - root of Rhode Island poker dealt from deck with both players holding stack
+func NewRoot(stack float32, deck cards.Deck) *RIGameState {
+	return Root(&Player{Id: acting.PlayerA, Stack: stack}, &Player{Id: acting.PlayerB, Stack: stack}, deck)
+}
+
 func Root(playerA *Player, playerB *Player, deck cards.Deck) *RIGameState {
 	chance := &Chance{id: acting.ChanceId, deck: deck}
 
@@ -209,35 +303,17 @@ func Root(playerA *Player, playerB *Player, deck cards.Deck) *RIGameState {
 }
 
 func createChild(blueprint *RIGameState, round rounds.PokerRound, action acting.Action, nextToMove acting.ActorID, terminal bool) *RIGameState {
+	parent := blueprint
+	if blueprint.undo != nil {
+		// mutable blueprint keeps moving, see Mutable
+		parent = blueprint.snapshot()
+	}
 	c := RIGameState{round: round,
-		parent: blueprint, causingAction: action, terminal: terminal,
+		parent: parent, causingAction: action, terminal: terminal,
 		table: blueprint.table.Clone(), actors: cloneActorsMap(blueprint.actors), nextToMove: nextToMove}
 	return &c
 }
 
-func (state *RIGameState) dealPublicCard(card *cards.Card) *RIGameState {
-
-	c := createChild(state, state.round.NextRound(), DealPublicCardAction{card}, acting.PlayerA, false)
-	// important to deal using child deck / not current chance deck
-	c.table.DropPublicCard(card)
-	c.actors[acting.ChanceId].(*Chance).deck.RemoveCard(card)
-	return c
-}
-
-func (state *RIGameState) dealPrivateCards(cardA *cards.Card, cardB *cards.Card) *RIGameState {
-
-	c := createChild(state, state.round.NextRound(), DealPrivateCardsAction{cardA, cardB}, acting.PlayerA, false)
-	// important to deal using child deck / not current chance deck
-	c.playerActor(acting.PlayerA).PlaceBet(c.table, Ante)
-	c.actors[acting.PlayerB].(*Player).PlaceBet(c.table, Ante)
-	c.playerActor(acting.PlayerA).CollectPrivateCard(cardA)
-	c.actors[acting.PlayerB].(*Player).CollectPrivateCard(cardB)
-	c.actors[acting.ChanceId].(*Chance).deck.RemoveCard(cardA)
-	c.actors[acting.ChanceId].(*Chance).deck.RemoveCard(cardB)
-
-	return c
-}
-
 func (state *RIGameState) chanceActions(chance *Chance) []acting.Action {
 	if state.round == rounds.Start {
 		deckSize := int(chance.deck.CardsLeft())
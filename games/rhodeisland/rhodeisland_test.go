@@ -1,6 +1,7 @@
 package rhodeisland
 
 import (
+	"bytes"
 	"github.com/int8/go-counterfactual-regret-minimization/acting"
 	"github.com/int8/go-counterfactual-regret-minimization/cards"
 	"github.com/int8/go-counterfactual-regret-minimization/games"
@@ -702,3 +703,332 @@ func createInformationSet(prvCard cards.Card, flopCard cards.Card, turnCard card
 	}
 	return informationSet
 }
+
+func TestNotationRoundTrip(t *testing.T) {
+	root := createRootForTest(100., 100.)
+	hands := []string{"-", "Th Qc |", "Th Qc | crr", "Th Qc | rc/", "Th Qc | rc/c As", "Th Qc | rc/rc/ As 2d", "Th Qc | cc/rrc/rf As 2d"}
+	for _, hand := range hands {
+		state, err := ParseState(root, hand)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state.String() != hand {
+			t.Errorf("Hand %v should be written back unchanged, got %v", hand, state.String())
+		}
+	}
+}
+
+func TestNotationBuildsSameStateAsActions(t *testing.T) {
+	root := createRootForTest(100., 100.)
+	state, err := ParseState(root, "Th Qc | crr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := []acting.Action{DealPrivateCardsAction{&cards.C10Hearts, &cards.QueenClubs}, CheckAction, BetAction, RaiseAction}
+	if state.InformationSet() != createInformationSet(cards.QueenClubs, cards.NoCard, cards.NoCard, actions) {
+		t.Error("Hand built from notation should have the same information set as the one built with Act")
+	}
+
+	state, err = ParseState(root, "Th Qc | rc/rc/cc As 2d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.IsTerminal() || state.Table().Pot != 70 || state.Evaluate() != -35 {
+		t.Errorf("Queen of clubs should win the showdown, got pot %v", state.Table().Pot)
+	}
+}
+
+func TestInvalidNotation(t *testing.T) {
+	root := createRootForTest(100., 100.)
+	hands := []string{"", "Th | c", "Th Th | c", "Th Qc | x", "Th Qc | c/", "Th Qc | rcc", "Th Qc | rc/c", "Th Qc | rc/c Th",
+		"Th Qc | rc As", "Th Qc | rc/ As Ks", "Th Qc | rf/", "Th Qc | rc// As"}
+	for _, hand := range hands {
+		if _, err := ParseState(root, hand); err == nil {
+			t.Errorf("Hand %q should be rejected", hand)
+		}
+	}
+}
+
+func TestDescribeInformationSet(t *testing.T) {
+	root := createRootForTest(100., 100.)
+	state, err := ParseState(root, "Th Qc | rc/rrc/c As 2d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	description, err := root.DescribeInformationSet(state.InformationSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if description.PrivateCards[0] != cards.QueenClubs || len(description.PublicCards) != 2 || description.PublicCards[1] != cards.C2Diamonds {
+		t.Errorf("Player B should hold queen of clubs with two public cards, got %+v", description)
+	}
+	if len(description.Rounds) != 3 || len(description.Rounds[1]) != 3 || description.Rounds[1][1] != acting.Raise || len(description.Rounds[2]) != 1 {
+		t.Errorf("Actions should be split into rounds, got %+v", description.Rounds)
+	}
+	if description.String() != "♣Q | ♠A ♦2 | B C / B R C / Ch" {
+		t.Errorf("Description should be readable, got %q", description)
+	}
+	if games.DescribeInformationSet(root, [3]byte{}) != "[0 0 0]" {
+		t.Error("Information sets of other games should be printed as they are")
+	}
+}
+
+func TestSuitIsomorphismIdentifiesRenamedSuits(t *testing.T) {
+	root := createRootForTest(100., 100.)
+	isomorphism := SuitIsomorphism{}
+	infSet := func(hand string) games.InformationSet {
+		state, err := ParseState(root, hand)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return state.InformationSet()
+	}
+
+	if isomorphism.Abstract(infSet("Ah Qc | rc/ Kh")) != isomorphism.Abstract(infSet("As Qd | rc/ Ks")) {
+		t.Error("Hands differing by suit names only should have the same abstract information set")
+	}
+	if isomorphism.Abstract(infSet("As Qc | rc/ Ks")) == isomorphism.Abstract(infSet("As Qc | rc/ Kh")) {
+		t.Error("Flush draw should not be identified with unsuited board")
+	}
+	if isomorphism.Abstract(infSet("Ah Qc | rc/rc/c Kd 2d")) == isomorphism.Abstract(infSet("Ah Qc | rc/rc/c Kd 2s")) {
+		t.Error("Suited board should not be identified with unsuited one")
+	}
+
+	raw := infSet("Th Qc | cc/r 2d")
+	expanded := isomorphism.Expand(isomorphism.Abstract(raw))
+	if len(expanded) != 12 {
+		t.Errorf("Two different suits can be named in 4*3 ways, got %v", len(expanded))
+	}
+	found := false
+	for _, informationSet := range expanded {
+		found = found || informationSet == raw
+		if isomorphism.Abstract(informationSet) != isomorphism.Abstract(raw) {
+			t.Error("Expanded information sets should share abstract information set")
+		}
+	}
+	if !found {
+		t.Error("Expansion should contain the original information set")
+	}
+}
+
+func TestSuitIsomorphismReducesInformationSets(t *testing.T) {
+	defer func(maxRaises int) { MaxRaises = maxRaises }(MaxRaises)
+	MaxRaises = 1
+	playerA := &Player{Id: acting.PlayerA, Actions: nil, Card: nil, Stack: 100.}
+	playerB := &Player{Id: acting.PlayerB, Actions: nil, Card: nil, Stack: 100.}
+	root := Root(playerA, playerB, cards.CreateLimitedDeck(cards.King, true))
+
+	raw, abstract := map[games.InformationSet]bool{}, map[games.InformationSet]bool{}
+	var collect func(state games.GameState)
+	collect = func(state games.GameState) {
+		if state.IsTerminal() {
+			return
+		}
+		if state.CurrentActor().GetID() != acting.ChanceId {
+			raw[state.InformationSet()] = true
+			abstract[SuitIsomorphism{}.Abstract(state.InformationSet())] = true
+		}
+		for _, action := range state.Actions() {
+			collect(state.Act(action))
+		}
+	}
+	collect(root)
+
+	// private card alone makes 4 times fewer information sets, public cards of other suits even more
+	ratio := float64(len(raw)) / float64(len(abstract))
+	if ratio < 4 || ratio > 24 {
+		t.Errorf("Suit isomorphism should reduce information sets 4 to 24 times, %v raw and %v abstract", len(raw), len(abstract))
+	}
+}
+
+func TestCardAbstractionOrdersBucketsByStrength(t *testing.T) {
+	abstraction, err := CreateCardAbstraction(cards.CreateLimitedDeck(cards.Jack, false), [3]int{2, 3, 4}, ExpectedHandStrength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if abstraction.Bucket(Hand{cards.AceHearts, cards.NoCard, cards.NoCard}) != 1 {
+		t.Error("Ace should be in the strongest preflop bucket")
+	}
+	if abstraction.Bucket(Hand{cards.JackHearts, cards.NoCard, cards.NoCard}) != 0 {
+		t.Error("Jack should be in the weakest preflop bucket")
+	}
+	if abstraction.Bucket(Hand{cards.AceHearts, cards.AceSpades, cards.AceClubs}) != 3 {
+		t.Error("Three of a kind should be in the strongest turn bucket")
+	}
+	buckets := map[int8]bool{}
+	for hand, bucket := range abstraction.Assignments {
+		if hand.Flop != cards.NoCard && hand.Turn == cards.NoCard {
+			buckets[bucket] = true
+		}
+	}
+	if len(buckets) != 3 {
+		t.Errorf("Flop hands should be clustered into 3 buckets, got %v", len(buckets))
+	}
+}
+
+func TestCardAbstractionSubstitutesBuckets(t *testing.T) {
+	abstraction, err := CreateCardAbstraction(cards.CreateLimitedDeck(cards.Jack, false), [3]int{2, 3, 4}, ExpectedHandStrengthSquared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := createRootForTest(100., 100.)
+	infSet := func(hand string) games.InformationSet {
+		state, err := ParseState(root, hand)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return state.InformationSet()
+	}
+
+	abstract := abstraction.Abstract(infSet("Ah Qc | rc/ Kh")).(BucketInformationSet)
+	if abstract.Buckets[2] != -1 || abstract.Buckets[0] < 0 || abstract.Buckets[1] < 0 {
+		t.Errorf("Only buckets of rounds reached should be set, got %v", abstract.Buckets)
+	}
+	if abstraction.Abstract(infSet("Ah Qc | rc/ Kh")) != abstraction.Abstract(infSet("As Qc | rc/ Ks")) {
+		t.Error("Hands of equal strength should share abstract information set")
+	}
+	if abstraction.Abstract(infSet("Ah Qc | rc/ Kh")) == abstraction.Abstract(infSet("Ah Qc | cc/ Kh")) {
+		t.Error("Abstract information sets should keep betting")
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := abstraction.Save(buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadCardAbstraction(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Abstract(infSet("Jh Qc | rc/rc/ Kh Jd")) != abstraction.Abstract(infSet("Jh Qc | rc/rc/ Kh Jd")) {
+		t.Error("Loaded abstraction should assign the same buckets")
+	}
+	if err := loaded.Bind(cards.CreateLimitedDeck(cards.Jack, false)); err != nil {
+		t.Errorf("Abstraction should bind to the deck it was created for, got %v", err)
+	}
+	for _, deck := range []cards.Deck{cards.CreateLimitedDeck(cards.C10, false), cards.CreateLimitedDeck(cards.Queen, false)} {
+		if err := loaded.Bind(deck); err == nil {
+			t.Errorf("Abstraction created for deck from Jack should not bind to deck of %v cards", len(deck.RemainingCards()))
+		}
+	}
+
+	abstraction.ImperfectRecall = true
+	if forgetting := abstraction.Abstract(infSet("Jh Qc | rc/rc/ Kh Jd")).(BucketInformationSet); forgetting.Buckets[0] != -1 || forgetting.Buckets[1] != -1 || forgetting.Buckets[2] < 0 {
+		t.Errorf("Imperfect recall abstraction should keep bucket of the current round only, got %v", forgetting.Buckets)
+	}
+
+	if _, err := CreateCardAbstraction(cards.CreateLimitedDeck(cards.Jack, false), [3]int{0, 3, 4}, ExpectedHandStrength); err == nil {
+		t.Error("Zero buckets should be rejected")
+	}
+}
+
+func TestApplyAndUndoFollowAct(t *testing.T) {
+	defer func(maxRaises int) { MaxRaises = maxRaises }(MaxRaises)
+	MaxRaises = 1
+	root := createLimitedDeckRootForTest(100., 100.)
+	mutable := root.Mutable().(*RIGameState)
+	deal := root.Actions()[0]
+	mutable.Apply(deal)
+
+	if terminals := compareWithAct(t, root.Act(deal).(*RIGameState), mutable); terminals == 0 {
+		t.Fatal("Hand should reach terminal states")
+	}
+	mutable.Undo()
+	if mutable.String() != root.String() || mutable.chanceActor().deck.CardsLeft() != 20 || mutable.table.Pot != 0 {
+		t.Errorf("Undo should restore the root, got %v with pot %v", mutable, mutable.table.Pot)
+	}
+	if len(root.Actions()) != len(mutable.Actions()) || root.Actions()[0] != mutable.Actions()[0] {
+		t.Error("Mutable root should deal the same cards")
+	}
+}
+
+func TestActOnMutableKeepsHistoryAfterUndo(t *testing.T) {
+	root := createLimitedDeckRootForTest(100., 100.)
+	mutable := root.Mutable()
+	mutable.Apply(root.Actions()[0])
+	actions := mutable.Actions()
+	mutable.Apply(actions[0])
+	next := mutable.Actions()
+	child := mutable.Act(next[len(next)-1]).(*RIGameState)
+	informationSet, history := child.InformationSet(), child.String()
+
+	mutable.Undo()
+	mutable.Apply(actions[1])
+	if child.InformationSet() != informationSet || child.String() != history {
+		t.Errorf("Child should keep its history %v after Undo and Apply, got %v", history, child)
+	}
+}
+
+// compareWithAct - walks subtree of state with Act and mutable with Apply and Undo, returns number of terminal states
+func compareWithAct(t *testing.T, state *RIGameState, mutable *RIGameState) int {
+	if state.String() != mutable.String() || state.IsTerminal() != mutable.IsTerminal() || state.table.Pot != mutable.table.Pot ||
+		state.stack(acting.PlayerA) != mutable.stack(acting.PlayerA) || state.stack(acting.PlayerB) != mutable.stack(acting.PlayerB) {
+		t.Fatalf("Mutable state %v differs from %v", mutable, state)
+	}
+	if state.IsTerminal() {
+		if state.Evaluate() != mutable.Evaluate() {
+			t.Fatalf("Mutable state %v evaluates differently", mutable)
+		}
+		return 1
+	}
+	if state.CurrentActor().GetID() != acting.ChanceId && state.InformationSet() != mutable.InformationSet() {
+		t.Fatalf("Information sets of %v differ", state)
+	}
+	terminals := 0
+	for _, action := range state.Actions() {
+		mutable.Apply(action)
+		terminals += compareWithAct(t, state.Act(action).(*RIGameState), mutable)
+		mutable.Undo()
+	}
+	return terminals
+}
+
+func createLimitedDeckRootForTest(playerAStack float32, playerBStack float32) *RIGameState {
+	playerA := &Player{Id: acting.PlayerA, Actions: nil, Card: nil, Stack: playerAStack}
+	playerB := &Player{Id: acting.PlayerB, Actions: nil, Card: nil, Stack: playerBStack}
+	return Root(playerA, playerB, cards.CreateLimitedDeck(cards.C10, true))
+}
+
+func benchmarkTraversal(b *testing.B, mutable bool) {
+	defer func(maxRaises int) { MaxRaises = maxRaises }(MaxRaises)
+	MaxRaises = 1
+	root := createLimitedDeckRootForTest(1000., 1000.)
+	deal := root.Act(root.Actions()[0]).(*RIGameState)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if mutable {
+			walkWithApply(deal.Mutable())
+		} else {
+			walkWithAct(deal)
+		}
+	}
+}
+
+func walkWithAct(state games.GameState) {
+	if state.IsTerminal() {
+		state.Evaluate()
+		return
+	}
+	for _, action := range state.Actions() {
+		walkWithAct(state.Act(action))
+	}
+}
+
+func walkWithApply(state games.MutableGameState) {
+	if state.IsTerminal() {
+		state.Evaluate()
+		return
+	}
+	for _, action := range state.Actions() {
+		state.Apply(action)
+		walkWithApply(state)
+		state.Undo()
+	}
+}
+
+func BenchmarkTraversalWithAct(b *testing.B) {
+	benchmarkTraversal(b, false)
+}
+
+func BenchmarkTraversalWithApplyAndUndo(b *testing.B) {
+	benchmarkTraversal(b, true)
+}
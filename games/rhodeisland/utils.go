@@ -24,7 +24,7 @@ func PrettyPrintInformationSet(infSet games.InformationSet) string {
 	cardsString := fmt.Sprintf("%v%v %v%v %v%v ",privateCardSymbol, privateCardSuit, flopCardSymbol, flopCardSuit, turnCardSymbol, turnCardSuit)
 	actionString := ""
 	for i := 21; ; i += 3 {
-		actionName := acting.ActionName(read3BitsFromByteArray(infSetArray, uint(i)))
+		actionName := acting.PokerActionName(read3BitsFromByteArray(infSetArray, uint(i)))
 		if actionName == acting.NoAction {
 			break
 		}
@@ -0,0 +1,178 @@
+package match
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/policy"
+)
+
+// Config - settings of head-to-head match
+type Config struct {
+	Hands int
+	// Threads - number of goroutines playing hands, 1 when not positive
+	Threads int
+	// Duplicate - hands are played in pairs with the same cards and swapped seats (Hands is rounded up to even number)
+	Duplicate bool
+	// BigBlind - chips in a big blind, winnings are reported in milli big blinds per hand
+	BigBlind float64
+	Seed     int64
+	KeepLogs bool
+}
+
+// HandLog - record of a single played hand
+type HandLog struct {
+	Hand int
+	// Seat - seat of the first policy
+	Seat    acting.ActorID
+	Actions []acting.Action
+	// Winnings - chips won by the first policy
+	Winnings float64
+}
+
+// Result - winnings of the first policy against the second one
+type Result struct {
+	Hands int
+	// MeanWinnings - chips won per hand
+	MeanWinnings float64
+	MbbPerHand   float64
+	// StdDev - standard deviation of winnings per hand (per pair of hands when duplicate) in mbb
+	StdDev float64
+	// ConfidenceInterval - half-width of 95% confidence interval of MbbPerHand
+	ConfidenceInterval float64
+	Logs               []HandLog
+}
+
+type accumulator struct {
+	samples    int
+	sum        float64
+	sumSquares float64
+}
+
+// Play - plays first policy against second one on games starting at root, seats alternate every hand
+func Play(root games.GameState, first policy.Policy, second policy.Policy, config Config) Result {
+	threads := config.Threads
+	if threads < 1 {
+		threads = 1
+	}
+	bigBlind := config.BigBlind
+	if bigBlind <= 0 {
+		bigBlind = 1.
+	}
+	handsPerSample := 1
+	if config.Duplicate {
+		handsPerSample = 2
+	}
+	samples := (config.Hands + handsPerSample - 1) / handsPerSample
+	hands := samples * handsPerSample
+
+	var logs []HandLog
+	if config.KeepLogs {
+		logs = make([]HandLog, hands)
+	}
+	accumulators := make([]accumulator, threads)
+	group := &sync.WaitGroup{}
+	for w := 0; w < threads; w++ {
+		group.Add(1)
+		go func(w int) {
+			defer group.Done()
+			rng := rand.New(rand.NewSource(config.Seed + int64(w)))
+			for sample := w; sample < samples; sample += threads {
+				script := &chanceScript{}
+				winnings := 0.
+				for i := 0; i < handsPerSample; i++ {
+					hand := sample*handsPerSample + i
+					seat := acting.PlayerA
+					if hand%2 == 1 {
+						seat = acting.PlayerB
+					}
+					script.rewind()
+					if !config.Duplicate {
+						script = &chanceScript{}
+					}
+					log := playHand(root, seat, first, second, script, rng, config.KeepLogs)
+					log.Hand = hand
+					winnings += log.Winnings
+					if config.KeepLogs {
+						logs[hand] = log
+					}
+				}
+				mbb := 1000. * winnings / float64(handsPerSample) / bigBlind
+				accumulators[w].samples++
+				accumulators[w].sum += mbb
+				accumulators[w].sumSquares += mbb * mbb
+			}
+		}(w)
+	}
+	group.Wait()
+
+	total := accumulator{}
+	for _, a := range accumulators {
+		total.samples += a.samples
+		total.sum += a.sum
+		total.sumSquares += a.sumSquares
+	}
+	result := Result{Hands: hands, Logs: logs}
+	if total.samples == 0 {
+		return result
+	}
+	n := float64(total.samples)
+	result.MbbPerHand = total.sum / n
+	result.MeanWinnings = result.MbbPerHand * bigBlind / 1000.
+	if total.samples > 1 {
+		variance := (total.sumSquares - n*result.MbbPerHand*result.MbbPerHand) / (n - 1)
+		result.StdDev = math.Sqrt(math.Max(variance, 0))
+		result.ConfidenceInterval = 1.96 * result.StdDev / math.Sqrt(n)
+	}
+	return result
+}
+
+func playHand(root games.GameState, seat acting.ActorID, first policy.Policy, second policy.Policy, script *chanceScript, rng *rand.Rand, keepLog bool) HandLog {
+	policies := map[acting.ActorID]policy.Policy{seat: first, -seat: second}
+	log := HandLog{Seat: seat}
+	state := root
+	for !state.IsTerminal() {
+		var action acting.Action
+		if state.CurrentActor().GetID() == acting.ChanceId {
+			action = script.draw(state, rng)
+		} else {
+			action = policies[state.CurrentActor().GetID()].Sample(state, rng)
+		}
+		if keepLog {
+			log.Actions = append(log.Actions, action)
+		}
+		state = state.Act(action)
+	}
+	log.Winnings = float64(seat) * float64(state.Evaluate())
+	return log
+}
+
+// chanceScript - chance actions of a hand, replayed in the second hand of a duplicate pair
+type chanceScript struct {
+	actions []acting.Action
+	next    int
+}
+
+func (script *chanceScript) rewind() {
+	script.next = 0
+}
+
+func (script *chanceScript) draw(state games.GameState, rng *rand.Rand) acting.Action {
+	actions := state.Actions()
+	if script.next < len(script.actions) {
+		for _, action := range actions {
+			if action == script.actions[script.next] {
+				script.next++
+				return action
+			}
+		}
+		script.actions = script.actions[:script.next]
+	}
+	action := actions[games.SampleIndex(rng, games.ChanceProbabilities(state, actions))]
+	script.actions = append(script.actions, action)
+	script.next++
+	return action
+}
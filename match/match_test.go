@@ -0,0 +1,87 @@
+package match
+
+import (
+	"math"
+	"testing"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/games/kuhn"
+	"github.com/int8/go-counterfactual-regret-minimization/games/rhodeisland"
+	"github.com/int8/go-counterfactual-regret-minimization/policy"
+)
+
+func TestAggressiveBotBeatsCheckFoldBot(t *testing.T) {
+	root := kuhn.NewRoot(100.)
+	result := Play(root, policy.AlwaysRaise, policy.CheckFold, Config{Hands: 1000, Threads: 4, BigBlind: 1.})
+
+	if result.Hands != 1000 {
+		t.Errorf("1000 hands should be played, %v played", result.Hands)
+	}
+	if math.Abs(result.MbbPerHand-1000.) > 1e-9 || math.Abs(result.MeanWinnings-1.) > 1e-9 {
+		t.Errorf("Aggressive bot should win the ante every hand, got %v mbb/hand", result.MbbPerHand)
+	}
+	if result.StdDev != 0 || result.ConfidenceInterval != 0 {
+		t.Errorf("Deterministic outcome should have no variance, got %v", result.StdDev)
+	}
+}
+
+func TestSeatsAlternate(t *testing.T) {
+	root := kuhn.NewRoot(100.)
+	result := Play(root, policy.Uniform{}, policy.Uniform{}, Config{Hands: 10, Threads: 3, KeepLogs: true})
+
+	if len(result.Logs) != 10 {
+		t.Fatalf("All 10 hands should be logged, %v logged", len(result.Logs))
+	}
+	for i, log := range result.Logs {
+		if log.Hand != i {
+			t.Errorf("Log %v should describe hand %v", i, log.Hand)
+		}
+		if (i%2 == 0) != (log.Seat == acting.PlayerA) {
+			t.Errorf("First policy should sit as player A in even hands only, hand %v", i)
+		}
+		if log.Actions[0].Name() != acting.DealPrivateCards {
+			t.Errorf("Hand %v should start with dealing private cards", i)
+		}
+	}
+}
+
+func TestDuplicateHandsShareCards(t *testing.T) {
+	defer func(maxRaises int) { rhodeisland.MaxRaises = maxRaises }(rhodeisland.MaxRaises)
+	rhodeisland.MaxRaises = 1
+	root := rhodeisland.NewRoot(1000., cards.CreateLimitedDeck(cards.C10, true))
+	result := Play(root, policy.AlwaysCall, policy.AlwaysCall, Config{Hands: 51, Threads: 2, Duplicate: true, KeepLogs: true, BigBlind: 10.})
+
+	if result.Hands != 52 {
+		t.Errorf("Duplicate match should round hands up to 52, got %v", result.Hands)
+	}
+	for i := 0; i < len(result.Logs); i += 2 {
+		first, second := result.Logs[i], result.Logs[i+1]
+		if len(first.Actions) != len(second.Actions) {
+			t.Fatalf("Calling stations should play identical hands in pair %v", i/2)
+		}
+		for j := range first.Actions {
+			if first.Actions[j] != second.Actions[j] {
+				t.Errorf("Hands of pair %v should share actions, %v != %v", i/2, first.Actions[j], second.Actions[j])
+			}
+		}
+		if first.Winnings != -second.Winnings {
+			t.Errorf("Swapped seats should cancel out card luck, %v and %v", first.Winnings, second.Winnings)
+		}
+	}
+	if result.MbbPerHand != 0 || result.StdDev != 0 {
+		t.Errorf("Identical bots should tie exactly in duplicate match, got %v", result.MbbPerHand)
+	}
+}
+
+func TestConfidenceIntervalCoversEvenMatch(t *testing.T) {
+	root := kuhn.NewRoot(100.)
+	result := Play(root, policy.Uniform{}, policy.Uniform{}, Config{Hands: 20000, Threads: 4, Seed: 7})
+
+	if result.ConfidenceInterval <= 0 {
+		t.Fatal("Random bots should produce positive confidence interval")
+	}
+	if math.Abs(result.MbbPerHand) > 2*result.ConfidenceInterval {
+		t.Errorf("Even match should be within confidence interval, got %v +/- %v", result.MbbPerHand, result.ConfidenceInterval)
+	}
+}
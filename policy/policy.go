@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"math/rand"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+)
+
+// Policy - decides how to act in (non-chance, non-terminal) states of a game
+type Policy interface {
+	ActionProbabilities(state games.GameState) map[acting.ActionName]float64
+	Sample(state games.GameState, rng *rand.Rand) acting.Action
+}
+
+// StrategyPolicy - acts according to a strategy (e.g. computed via CFR), falls back to another policy for unseen information sets
+type StrategyPolicy struct {
+	Strategy cfr.StrategyMap
+	Fallback Policy
+}
+
+// NewStrategyPolicy - policy for strategy, unseen information sets are played by fallback (uniformly random when nil)
+func NewStrategyPolicy(strategy cfr.StrategyMap, fallback Policy) *StrategyPolicy {
+	if fallback == nil {
+		fallback = Uniform{}
+	}
+	return &StrategyPolicy{Strategy: strategy, Fallback: fallback}
+}
+
+func (policy *StrategyPolicy) ActionProbabilities(state games.GameState) map[acting.ActionName]float64 {
+	strategy, ok := policy.Strategy.Value[state.InformationSet()]
+	if !ok {
+		return policy.Fallback.ActionProbabilities(state)
+	}
+	probabilities := map[acting.ActionName]float64{}
+	sum := 0.
+	for _, action := range state.Actions() {
+		probability := float64(strategy[action.Name()])
+		if probability > 0 {
+			probabilities[action.Name()] = probability
+			sum += probability
+		}
+	}
+	if sum <= 0 {
+		return policy.Fallback.ActionProbabilities(state)
+	}
+	for name := range probabilities {
+		probabilities[name] /= sum
+	}
+	return probabilities
+}
+
+func (policy *StrategyPolicy) Sample(state games.GameState, rng *rand.Rand) acting.Action {
+	return sample(state, policy.ActionProbabilities(state), rng)
+}
+
+// Uniform - picks every available action with the same probability
+type Uniform struct{}
+
+func (Uniform) ActionProbabilities(state games.GameState) map[acting.ActionName]float64 {
+	actions := state.Actions()
+	probabilities := make(map[acting.ActionName]float64, len(actions))
+	for _, action := range actions {
+		probabilities[action.Name()] = 1. / float64(len(actions))
+	}
+	return probabilities
+}
+
+func (policy Uniform) Sample(state games.GameState, rng *rand.Rand) acting.Action {
+	return sample(state, policy.ActionProbabilities(state), rng)
+}
+
+// Fixed - rule based bot, always picks first available action from its preferences (first available action if none is)
+type Fixed struct {
+	Preferences []acting.ActionName
+}
+
+var (
+	// AlwaysCall - passive bot, never folds and never bets
+	AlwaysCall = NewFixedPolicy(acting.Check, acting.Call)
+	// AlwaysRaise - aggressive bot, bets and raises whenever it can
+	AlwaysRaise = NewFixedPolicy(acting.Raise, acting.Bet, acting.Call, acting.Check)
+	// CheckFold - gives up whenever facing a bet
+	CheckFold = NewFixedPolicy(acting.Check, acting.Fold)
+)
+
+// NewFixedPolicy - rule based bot preferring actions in given order
+func NewFixedPolicy(preferences ...acting.ActionName) Fixed {
+	return Fixed{Preferences: preferences}
+}
+
+func (policy Fixed) ActionProbabilities(state games.GameState) map[acting.ActionName]float64 {
+	actions := state.Actions()
+	for _, preference := range policy.Preferences {
+		for _, action := range actions {
+			if action.Name() == preference {
+				return map[acting.ActionName]float64{preference: 1.}
+			}
+		}
+	}
+	return map[acting.ActionName]float64{actions[0].Name(): 1.}
+}
+
+func (policy Fixed) Sample(state games.GameState, rng *rand.Rand) acting.Action {
+	return sample(state, policy.ActionProbabilities(state), rng)
+}
+
+// sample - draws one of the state actions, rng defaults to math/rand global source when nil
+func sample(state games.GameState, probabilities map[acting.ActionName]float64, rng *rand.Rand) acting.Action {
+	actions := state.Actions()
+	actionProbabilities := make([]float32, len(actions))
+	for i, action := range actions {
+		actionProbabilities[i] = float32(probabilities[action.Name()])
+	}
+	return actions[games.SampleIndex(rng, actionProbabilities)]
+}
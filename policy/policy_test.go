@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/kuhn"
+)
+
+func TestStrategyPolicyNormalizesLegalActions(t *testing.T) {
+	state := createStateForTest()
+	strategy := cfr.NewStrategyMap()
+	strategy.Value[state.InformationSet()] = map[acting.ActionName]float32{acting.Check: 1., acting.Bet: 3., acting.Raise: 4.}
+
+	probabilities := NewStrategyPolicy(strategy, nil).ActionProbabilities(state)
+
+	if len(probabilities) != 2 || math.Abs(probabilities[acting.Bet]-0.75) > 1e-9 || math.Abs(probabilities[acting.Check]-0.25) > 1e-9 {
+		t.Errorf("Illegal actions should be dropped and the rest normalized, got %v", probabilities)
+	}
+}
+
+func TestStrategyPolicyFallsBackForUnseenInformationSets(t *testing.T) {
+	state := createStateForTest()
+	unseen := NewStrategyPolicy(cfr.NewStrategyMap(), nil).ActionProbabilities(state)
+	if unseen[acting.Check] != 0.5 || unseen[acting.Bet] != 0.5 {
+		t.Errorf("Unseen information set should be played uniformly by default, got %v", unseen)
+	}
+
+	strategy := cfr.NewStrategyMap()
+	strategy.Value[state.InformationSet()] = map[acting.ActionName]float32{acting.Check: 0., acting.Bet: 0.}
+	zeroes := NewStrategyPolicy(strategy, AlwaysRaise).ActionProbabilities(state)
+	if zeroes[acting.Bet] != 1. {
+		t.Errorf("Information set without positive probabilities should be played by fallback, got %v", zeroes)
+	}
+}
+
+func TestFixedPolicyPicksFirstAvailablePreference(t *testing.T) {
+	state := createStateForTest()
+	if action := AlwaysRaise.Sample(state, nil); action.Name() != acting.Bet {
+		t.Errorf("Aggressive bot should bet when raise is not available, got %v", action.Name())
+	}
+	if action := AlwaysCall.Sample(state, nil); action.Name() != acting.Check {
+		t.Errorf("Passive bot should check, got %v", action.Name())
+	}
+	facingBet := state.Act(kuhn.BetAction)
+	if action := CheckFold.Sample(facingBet, nil); action.Name() != acting.Fold {
+		t.Errorf("Check-fold bot should fold facing bet, got %v", action.Name())
+	}
+	if action := NewFixedPolicy(acting.Raise).Sample(facingBet, nil); action != facingBet.Actions()[0] {
+		t.Errorf("Bot without available preference should pick first action, got %v", action.Name())
+	}
+}
+
+func TestSampleFollowsProbabilities(t *testing.T) {
+	state := createStateForTest()
+	strategy := cfr.NewStrategyMap()
+	strategy.Value[state.InformationSet()] = map[acting.ActionName]float32{acting.Check: 0.2, acting.Bet: 0.8}
+	policy := NewStrategyPolicy(strategy, nil)
+	rng := rand.New(rand.NewSource(42))
+
+	bets := 0
+	for i := 0; i < 10000; i++ {
+		if policy.Sample(state, rng).Name() == acting.Bet {
+			bets++
+		}
+	}
+	if bets < 7700 || bets > 8300 {
+		t.Errorf("Bet should be sampled roughly 8000 out of 10000 times, got %v", bets)
+	}
+}
+
+func createStateForTest() games.GameState {
+	return kuhn.NewRoot(100.).Act(kuhn.DealPrivateCardsAction{CardA: &cards.KingHearts, CardB: &cards.JackHearts})
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+)
+
+type htmlGroup struct {
+	Round       string
+	PrivateCard string
+	Rows        []htmlRow
+}
+
+type htmlRow struct {
+	Description string
+	Class       string
+	Cells       []htmlCell
+}
+
+type htmlBelief struct {
+	Public      string
+	Probability string
+	Players     []htmlRange
+}
+
+type htmlRange struct {
+	Player string
+	Cells  []htmlCell
+}
+
+type htmlCell struct {
+	Text  string
+	Style template.CSS
+}
+
+var page = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: right; }
+td.infoset { text-align: left; font-family: monospace; }
+tr.pure td.infoset { border-left: 6px solid #2e7d32; }
+tr.mixed td.infoset { border-left: 6px solid #ef6c00; }
+.legend span { margin-right: 2em; padding-left: 0.5em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="legend">{{.InformationSets}} information sets,
+<span style="border-left: 6px solid #2e7d32">near-pure (one action at least {{.Pure}})</span>
+<span style="border-left: 6px solid #ef6c00">heavily mixed (normalized entropy at least {{.Mixed}})</span></p>
+{{range .Groups}}
+<h2>{{.Round}}, private card {{.PrivateCard}}</h2>
+<table>
+<tr><th>information set</th>{{range $.Actions}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr class="{{.Class}}"><td class="infoset">{{.Description}}</td>{{range .Cells}}<td style="{{.Style}}">{{.Text}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}
+{{if .Beliefs}}<h2>Ranges</h2>{{end}}
+{{range .Beliefs}}
+<h3>{{.Public}} (reached with probability {{.Probability}})</h3>
+<table>
+{{range .Players}}<tr><th>player {{.Player}}</th>{{range .Cells}}<td style="{{.Style}}">{{.Text}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML - self-contained page with a table per round and private card, cells are shaded by action frequency
+func (report *Report) WriteHTML(w io.Writer, title string) error {
+	groups := []htmlGroup{}
+	for _, row := range report.Rows {
+		if len(groups) == 0 || groups[len(groups)-1].Round != row.Round || groups[len(groups)-1].PrivateCard != row.PrivateCard {
+			groups = append(groups, htmlGroup{Round: row.Round, PrivateCard: row.PrivateCard})
+		}
+		htmlRow := htmlRow{Description: row.Description, Class: row.Class.String()}
+		for i, text := range report.frequencies(row, "%.3f") {
+			cell := htmlCell{Text: text}
+			if frequency, ok := row.Frequencies[report.Actions[i]]; ok {
+				cell.Style = template.CSS(fmt.Sprintf("background-color: rgba(21, 101, 192, %.2f)", frequency*0.8))
+			}
+			htmlRow.Cells = append(htmlRow.Cells, cell)
+		}
+		groups[len(groups)-1].Rows = append(groups[len(groups)-1].Rows, htmlRow)
+	}
+	beliefs := []htmlBelief{}
+	for _, computed := range report.Beliefs {
+		htmlBelief := htmlBelief{Public: computed.Public.String(), Probability: fmt.Sprintf("%.4f", computed.Probability)}
+		for _, player := range []acting.ActorID{acting.PlayerA, acting.PlayerB} {
+			htmlRange := htmlRange{Player: acting.PlayerName(player)}
+			for _, card := range computed.Cards(player) {
+				probability := computed.Ranges[player][card]
+				htmlRange.Cells = append(htmlRange.Cells, htmlCell{Text: fmt.Sprintf("%v %.3f", card, probability),
+					Style: template.CSS(fmt.Sprintf("background-color: rgba(21, 101, 192, %.2f)", probability*0.8))})
+			}
+			htmlBelief.Players = append(htmlBelief.Players, htmlRange)
+		}
+		beliefs = append(beliefs, htmlBelief)
+	}
+	return page.Execute(w, map[string]interface{}{
+		"Title":           title,
+		"InformationSets": len(report.Rows),
+		"Pure":            PureThreshold,
+		"Mixed":           MixedThreshold,
+		"Actions":         report.actionHeaders(),
+		"Groups":          groups,
+		"Beliefs":         beliefs,
+	})
+}
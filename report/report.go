@@ -0,0 +1,205 @@
+// Package report - readable summaries of strategies: per information set action frequencies grouped by round and private card
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/belief"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/rounds"
+)
+
+// PureThreshold - information sets playing one action at least that often are near-pure
+const PureThreshold = 0.95
+
+// MixedThreshold - information sets with normalized entropy of action frequencies at least that high are heavily mixed
+const MixedThreshold = 0.9
+
+type Class int8
+
+const (
+	Regular Class = iota
+	Pure
+	Mixed
+)
+
+func (class Class) String() string {
+	switch class {
+	case Pure:
+		return "pure"
+	case Mixed:
+		return "mixed"
+	}
+	return ""
+}
+
+// Row - action frequencies of a single information set
+type Row struct {
+	InformationSet games.InformationSet
+	// Description - information set decoded by the game, raw value when game does not describe its information sets
+	Description string
+	Round       string
+	PrivateCard string
+	Frequencies map[acting.ActionName]float64
+	Class       Class
+	round       int
+	card        *cards.Card
+}
+
+// Report - strategy of a game, rows are sorted by round, private card and description
+type Report struct {
+	Rows []Row
+	// Actions - all actions played in any information set, in order of report columns
+	Actions []acting.ActionName
+	// Beliefs - ranges of both players in public states of interest, not included in CSV
+	Beliefs []belief.Belief
+}
+
+// New - report of strategy computed for game (root of its game tree)
+func New(game games.GameState, strategy cfr.StrategyMap) *Report {
+	report := &Report{}
+	describer, _ := game.(games.InformationSetDescriber)
+	seenActions := map[acting.ActionName]bool{}
+	for infSet, actions := range strategy.Value {
+		row := Row{InformationSet: infSet, Description: fmt.Sprintf("%v", infSet), Round: "-", PrivateCard: "-", Frequencies: cfr.Normalize(actions)}
+		if describer != nil {
+			if description, err := describer.DescribeInformationSet(infSet); err == nil {
+				row.Description = description.String()
+				row.round = len(description.Rounds)
+				row.Round = rounds.PokerRound(row.round).String()
+				if len(description.PrivateCards) > 0 {
+					row.card = &description.PrivateCards[0]
+					row.PrivateCard = row.card.String()
+				}
+			}
+		}
+		row.Class = classify(row.Frequencies)
+		for name := range row.Frequencies {
+			seenActions[name] = true
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool { return report.Rows[i].less(report.Rows[j]) })
+	for name := range seenActions {
+		report.Actions = append(report.Actions, name)
+	}
+	sort.Slice(report.Actions, func(i, j int) bool { return actionOrder(report.Actions[i]) < actionOrder(report.Actions[j]) })
+	return report
+}
+
+// AddRanges - adds ranges of both players in public states when both follow strategy
+func (report *Report) AddRanges(root games.PokerGameState, strategy cfr.StrategyMap, publicStates ...belief.PublicState) error {
+	for _, public := range publicStates {
+		computed, err := belief.Compute(root, strategy, public)
+		if err != nil {
+			return err
+		}
+		report.Beliefs = append(report.Beliefs, computed)
+	}
+	return nil
+}
+
+// WriteText - aligned plain text table, ranges follow it
+func (report *Report) WriteText(w io.Writer) error {
+	writer := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(writer, "round\tcard\tinformation set\t%v\tclass\n", strings.Join(report.actionHeaders(), "\t"))
+	for _, row := range report.Rows {
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\n", row.Round, row.PrivateCard, row.Description, strings.Join(report.frequencies(row, "%.3f"), "\t"), row.Class)
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	for _, computed := range report.Beliefs {
+		fmt.Fprintln(w)
+		computed.Write(w)
+	}
+	return nil
+}
+
+// WriteCSV - one line per information set, one column per action
+func (report *Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := append([]string{"round", "private_card", "information_set"}, report.actionHeaders()...)
+	if err := writer.Write(append(header, "class")); err != nil {
+		return err
+	}
+	for _, row := range report.Rows {
+		record := append([]string{row.Round, row.PrivateCard, row.Description}, report.frequencies(row, "%.6f")...)
+		if err := writer.Write(append(record, row.Class.String())); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (report *Report) actionHeaders() []string {
+	headers := make([]string, len(report.Actions))
+	for i, name := range report.Actions {
+		headers[i] = name.Word()
+	}
+	return headers
+}
+
+// frequencies - formatted frequencies of all report actions, empty for actions not available in the information set
+func (report *Report) frequencies(row Row, format string) []string {
+	texts := make([]string, len(report.Actions))
+	for i, name := range report.Actions {
+		if frequency, ok := row.Frequencies[name]; ok {
+			texts[i] = fmt.Sprintf(format, frequency)
+		}
+	}
+	return texts
+}
+
+func (row Row) less(other Row) bool {
+	if row.round != other.round {
+		return row.round < other.round
+	}
+	if row.card != nil && other.card != nil && *row.card != *other.card {
+		rank, otherRank := cards.CardSymbol2Int(row.card.Symbol), cards.CardSymbol2Int(other.card.Symbol)
+		if rank != otherRank {
+			return rank > otherRank
+		}
+		return row.PrivateCard < other.PrivateCard
+	}
+	return row.Description < other.Description
+}
+
+func classify(frequencies map[acting.ActionName]float64) Class {
+	if len(frequencies) < 2 {
+		return Regular
+	}
+	entropy := 0.
+	for _, frequency := range frequencies {
+		if frequency >= PureThreshold {
+			return Pure
+		}
+		if frequency > 0 {
+			entropy -= frequency * math.Log(frequency)
+		}
+	}
+	if entropy/math.Log(float64(len(frequencies))) >= MixedThreshold {
+		return Mixed
+	}
+	return Regular
+}
+
+func actionOrder(name acting.ActionName) int {
+	order := 0
+	for i, bit := range name {
+		if bit {
+			order += 1 << uint(i)
+		}
+	}
+	return order
+}
@@ -0,0 +1,122 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/belief"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/bundled"
+)
+
+func TestKuhnReport(t *testing.T) {
+	report := createKuhnReport(t)
+
+	if len(report.Rows) != 12 {
+		t.Fatalf("Kuhn Poker has 12 information sets, got %v", len(report.Rows))
+	}
+	if len(report.Actions) != 4 || report.Actions[0] != acting.Fold || report.Actions[3] != acting.Call {
+		t.Errorf("Report should have fold, check, bet and call columns, got %v", report.Actions)
+	}
+	if report.Rows[0].PrivateCard != "♥K" || report.Rows[len(report.Rows)-1].PrivateCard != "♥J" {
+		t.Errorf("Rows should be sorted from the highest private card, got %v first", report.Rows[0].PrivateCard)
+	}
+	for _, row := range report.Rows {
+		if row.Round != "Preflop" {
+			t.Errorf("Kuhn Poker has single betting round, got %v", row.Round)
+		}
+		// king always calls a bet, jack always folds facing one
+		if strings.HasSuffix(row.Description, "| B") && row.PrivateCard != "♥Q" && row.Class != Pure {
+			t.Errorf("Information set %v should be near-pure, got %v", row.Description, row.Frequencies)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	if classify(map[acting.ActionName]float64{acting.Call: 0.97, acting.Fold: 0.03}) != Pure {
+		t.Error("Information set playing one action 97% of time should be near-pure")
+	}
+	if classify(map[acting.ActionName]float64{acting.Call: 0.5, acting.Fold: 0.48, acting.Raise: 0.02}) != Regular {
+		t.Error("Information set never raising is not heavily mixed")
+	}
+	if classify(map[acting.ActionName]float64{acting.Call: 0.55, acting.Fold: 0.45}) != Mixed {
+		t.Error("Information set playing two actions almost equally should be heavily mixed")
+	}
+}
+
+func TestReportFormats(t *testing.T) {
+	report := createKuhnReport(t)
+
+	buffer := &bytes.Buffer{}
+	if err := report.WriteCSV(buffer); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(buffer).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 13 || strings.Join(records[0], ",") != "round,private_card,information_set,fold,check,bet,call,class" {
+		t.Errorf("CSV should have header and a line per information set, got %v", records[0])
+	}
+
+	buffer.Reset()
+	if err := report.WriteText(buffer); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(buffer.String(), "\n") != 13 || !strings.Contains(buffer.String(), "♥K | - | Ch B") {
+		t.Errorf("Text report should list information sets, got:\n%v", buffer.String())
+	}
+
+	buffer.Reset()
+	if err := report.WriteHTML(buffer, "Kuhn <Poker>"); err != nil {
+		t.Fatal(err)
+	}
+	page := buffer.String()
+	if !strings.Contains(page, "<title>Kuhn &lt;Poker&gt;</title>") || strings.Count(page, "<table>") != 3 {
+		t.Errorf("Page should have escaped title and a table per private card, got:\n%v", page)
+	}
+	if !strings.Contains(page, `<tr class="pure">`) || !strings.Contains(page, "background-color: rgba(21, 101, 192,") {
+		t.Errorf("Page should highlight near-pure information sets and shade frequencies")
+	}
+}
+
+func TestReportRanges(t *testing.T) {
+	root, _ := bundled.Config{Game: bundled.Kuhn}.Root()
+	strategy := cfr.CreateComputingRoutine(root).ComputeNashEquilibriumViaCFR(20000, 1)
+	report := New(root, strategy)
+	if err := report.AddRanges(root.(games.PokerGameState), strategy, belief.PublicState{Betting: "cr"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := report.AddRanges(root.(games.PokerGameState), strategy, belief.PublicState{Betting: "rr"}); err == nil {
+		t.Error("Ranges of impossible public states should be rejected")
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := report.WriteText(buffer); err != nil {
+		t.Fatal(err)
+	}
+	// player B bets after check with king or as a bluff with jack, never with queen
+	if !strings.Contains(buffer.String(), "public state cr") || !strings.Contains(buffer.String(), "player B: ♥K") || strings.Contains(buffer.String(), "player B: ♥K 0.500, ♥Q") {
+		t.Errorf("Text report should end with ranges, got:\n%v", buffer.String())
+	}
+	buffer.Reset()
+	if err := report.WriteHTML(buffer, "Kuhn"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffer.String(), "<h3>cr (reached with probability") {
+		t.Error("Page should show ranges")
+	}
+}
+
+func createKuhnReport(t *testing.T) *Report {
+	root, err := bundled.Config{Game: bundled.Kuhn}.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	strategy := cfr.CreateComputingRoutine(root).ComputeNashEquilibriumViaCFR(20000, 1)
+	return New(root, strategy)
+}
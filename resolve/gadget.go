@@ -0,0 +1,197 @@
+package resolve
+
+import (
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+)
+
+// gadgetInformationSet - opponent choosing whether to enter the subgame knows his private card only
+type gadgetInformationSet struct {
+	Card cards.Card
+}
+
+type actor struct {
+	id acting.ActorID
+}
+
+func (a actor) GetID() acting.ActorID {
+	return a.id
+}
+
+// gadgetAction - deal of private cards (index of deal) or opponent choice
+type gadgetAction struct {
+	name  acting.ActionName
+	index int
+}
+
+func (action gadgetAction) Name() acting.ActionName {
+	return action.name
+}
+
+var (
+	// follow - opponent enters the subgame
+	follow = gadgetAction{name: acting.Call}
+	// terminate - opponent takes value of his best response to blueprint
+	terminate = gadgetAction{name: acting.Fold}
+)
+
+// gadgetRoot - chance dealing private cards of subgame
+type gadgetRoot struct {
+	subgame       *Subgame
+	states        []games.PokerGameState
+	probabilities []float32
+	// alternatives - value of terminating for opponent private cards (from opponent perspective)
+	alternatives map[cards.Card]float32
+}
+
+func (root *gadgetRoot) Parent() games.GameState {
+	return nil
+}
+
+func (root *gadgetRoot) Act(action acting.Action) games.GameState {
+	return &gadgetChoice{root: root, state: root.states[action.(gadgetAction).index]}
+}
+
+func (root *gadgetRoot) InformationSet() games.InformationSet {
+	return nil
+}
+
+func (root *gadgetRoot) Actions() []acting.Action {
+	actions := make([]acting.Action, len(root.states))
+	for i := range actions {
+		actions[i] = gadgetAction{name: acting.DealPrivateCards, index: i}
+	}
+	return actions
+}
+
+func (root *gadgetRoot) IsTerminal() bool {
+	return false
+}
+
+func (root *gadgetRoot) CurrentActor() acting.Actor {
+	return actor{acting.ChanceId}
+}
+
+func (root *gadgetRoot) Evaluate() float32 {
+	panic("gadget root is not terminal")
+}
+
+func (root *gadgetRoot) ChanceProbabilities() []float32 {
+	return root.probabilities
+}
+
+// gadgetChoice - opponent of re-solving player enters the subgame or terminates
+type gadgetChoice struct {
+	root  *gadgetRoot
+	state games.PokerGameState
+}
+
+func (choice *gadgetChoice) opponent() acting.ActorID {
+	return -choice.root.subgame.Player
+}
+
+func (choice *gadgetChoice) Parent() games.GameState {
+	return choice.root
+}
+
+func (choice *gadgetChoice) Act(action acting.Action) games.GameState {
+	if action.Name() == terminate.Name() {
+		value := float32(choice.opponent()) * choice.root.alternatives[*choice.state.PrivateCard(choice.opponent())]
+		return &gadgetTerminal{parent: choice, value: value}
+	}
+	return &node{parent: choice, state: choice.state, subgame: choice.root.subgame}
+}
+
+func (choice *gadgetChoice) InformationSet() games.InformationSet {
+	return gadgetInformationSet{*choice.state.PrivateCard(choice.opponent())}
+}
+
+func (choice *gadgetChoice) Actions() []acting.Action {
+	return []acting.Action{follow, terminate}
+}
+
+func (choice *gadgetChoice) IsTerminal() bool {
+	return false
+}
+
+func (choice *gadgetChoice) CurrentActor() acting.Actor {
+	return actor{choice.opponent()}
+}
+
+func (choice *gadgetChoice) Evaluate() float32 {
+	panic("gadget choice is not terminal")
+}
+
+// gadgetTerminal - opponent terminated, value is payoff of player A
+type gadgetTerminal struct {
+	parent games.GameState
+	value  float32
+}
+
+func (terminal *gadgetTerminal) Parent() games.GameState {
+	return terminal.parent
+}
+
+func (terminal *gadgetTerminal) Act(action acting.Action) games.GameState {
+	panic("gadget terminal has no actions")
+}
+
+func (terminal *gadgetTerminal) InformationSet() games.InformationSet {
+	return nil
+}
+
+func (terminal *gadgetTerminal) Actions() []acting.Action {
+	return []acting.Action{}
+}
+
+func (terminal *gadgetTerminal) IsTerminal() bool {
+	return true
+}
+
+func (terminal *gadgetTerminal) CurrentActor() acting.Actor {
+	return actor{acting.ChanceId}
+}
+
+func (terminal *gadgetTerminal) Evaluate() float32 {
+	return terminal.value
+}
+
+// node - state of the subgame, states after its last betting round are terminal and valued by blueprint
+type node struct {
+	parent  games.GameState
+	state   games.GameState
+	subgame *Subgame
+}
+
+func (n *node) Parent() games.GameState {
+	return n.parent
+}
+
+func (n *node) Act(action acting.Action) games.GameState {
+	return &node{parent: n, state: n.state.Act(action), subgame: n.subgame}
+}
+
+func (n *node) InformationSet() games.InformationSet {
+	return n.state.InformationSet()
+}
+
+func (n *node) Actions() []acting.Action {
+	return n.state.Actions()
+}
+
+func (n *node) IsTerminal() bool {
+	return n.subgame.isLeaf(n.state)
+}
+
+func (n *node) CurrentActor() acting.Actor {
+	return n.state.CurrentActor()
+}
+
+func (n *node) Evaluate() float32 {
+	return n.subgame.evaluate(n.state)
+}
+
+func (n *node) ChanceProbabilities() []float32 {
+	return games.ChanceProbabilities(n.state, n.state.Actions())
+}
@@ -0,0 +1,247 @@
+// Package resolve - depth-limited re-solving of a blueprint strategy during play
+//
+// Subgame is rooted at the public state of the hand (betting and public cards) and contains every deal of private cards
+// consistent with it. Ranges of both players are derived by Bayes rule from blueprint reach probabilities. Re-solving is
+// safe: in the gadget game opponent of the re-solving player first chooses, for his private card, between entering the
+// subgame and terminating with value his best response to the blueprint would get, so the re-solved strategy can not
+// do worse than the blueprint against any opponent. Subgame is depth-limited: states of later betting rounds are leaves
+// valued by the blueprint played by both players till the end of the hand.
+package resolve
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/belief"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/policy"
+	"github.com/int8/go-counterfactual-regret-minimization/rounds"
+)
+
+// batch - CFR iterations run between checks of time budget
+const batch = 100
+
+// Subgame - subgame of a public state along with its re-solving gadget
+type Subgame struct {
+	// Player - re-solving player, the one to act in the public state
+	Player acting.ActorID
+	// Ranges - probabilities of private cards of both players in the public state
+	Ranges    map[acting.ActorID]map[cards.Card]float64
+	blueprint cfr.StrategyMap
+	gadget    *gadgetRoot
+	// rounds - betting rounds included in subgame (1 - current round only)
+	rounds     int
+	startRound rounds.PokerRound
+	leafValues map[string]float32
+	mutex      *sync.Mutex
+}
+
+// NewSubgame - subgame of public state reached from root by history, including given number of betting rounds (at least 1)
+func NewSubgame(root games.PokerGameState, blueprint cfr.StrategyMap, history []acting.Action, bettingRounds int) (*Subgame, error) {
+	if bettingRounds < 1 {
+		return nil, errors.New("resolve: subgame has to include at least one betting round")
+	}
+	if len(history) == 0 || history[0].Name() != acting.DealPrivateCards {
+		return nil, errors.New("resolve: history should start with private cards")
+	}
+	current := games.GameState(root)
+	for _, action := range history {
+		current = current.Act(action)
+	}
+	state := current.(games.PokerGameState)
+	if state.IsTerminal() || state.CurrentActor().GetID() == acting.ChanceId {
+		return nil, errors.New("resolve: player has to act in the public state")
+	}
+
+	subgame := &Subgame{Player: state.CurrentActor().GetID(), blueprint: blueprint, rounds: bettingRounds, startRound: state.Round(),
+		leafValues: map[string]float32{}, mutex: &sync.Mutex{}}
+	public := belief.PublicStateOf(state, history)
+	deals, err := belief.Deals(root, blueprint, public)
+	if err != nil {
+		return nil, err
+	}
+	// opponent may play differently than blueprint says, deals re-solving player does not reach are dropped only
+	reached := []belief.Deal{}
+	for _, deal := range deals {
+		if deal.Reach[subgame.Player] > 0 {
+			reached = append(reached, deal)
+		}
+	}
+	if len(reached) == 0 {
+		return nil, errors.New("resolve: public state can not be reached by blueprint")
+	}
+	// ranges are empty when blueprint of opponent never reaches the public state
+	beliefs, _ := belief.FromDeals(public, deals)
+	subgame.Ranges = beliefs.Ranges
+	subgame.gadget = subgame.createGadget(reached)
+	return subgame, nil
+}
+
+// Gadget - root of the re-solving gadget game
+func (subgame *Subgame) Gadget() games.GameState {
+	return subgame.gadget
+}
+
+// Solve - runs CFR on the gadget for time budget (at least one batch of iterations), returns strategy of subgame information sets
+func (subgame *Subgame) Solve(budget time.Duration) cfr.StrategyMap {
+	routine := cfr.CreateComputingRoutine(subgame.gadget)
+	deadline := time.Now().Add(budget)
+	var strategy cfr.StrategyMap
+	for done := false; !done; done = time.Now().After(deadline) {
+		strategy = routine.ComputeNashEquilibriumViaCFR(batch, 1)
+	}
+	for infSet := range strategy.Value {
+		if _, ok := infSet.(gadgetInformationSet); ok {
+			delete(strategy.Value, infSet)
+		}
+	}
+	return strategy
+}
+
+// Policy - re-solved strategy, blueprint is played in information sets outside of subgame
+func (subgame *Subgame) Policy(resolved cfr.StrategyMap) policy.Policy {
+	return policy.NewStrategyPolicy(resolved, policy.NewStrategyPolicy(subgame.blueprint, nil))
+}
+
+// createGadget - deals are weighted by probability of chance and re-solving player reaching them (opponent reach is replaced by his choice)
+func (subgame *Subgame) createGadget(deals []belief.Deal) *gadgetRoot {
+	opponent := -subgame.Player
+	gadget := &gadgetRoot{subgame: subgame}
+	byCard := map[cards.Card][]weightedState{}
+	sum := 0.
+	for _, d := range deals {
+		weight := d.Chance * d.Reach[subgame.Player]
+		card := *d.State.PrivateCard(opponent)
+		byCard[card] = append(byCard[card], weightedState{d.State, weight})
+		gadget.states = append(gadget.states, d.State)
+		gadget.probabilities = append(gadget.probabilities, float32(weight))
+		sum += weight
+	}
+	for i := range gadget.probabilities {
+		gadget.probabilities[i] /= float32(sum)
+	}
+	// opponent may terminate with value of his best response to blueprint
+	gadget.alternatives = map[cards.Card]float32{}
+	for card, states := range byCard {
+		weight := 0.
+		for _, s := range states {
+			weight += s.weight
+		}
+		gadget.alternatives[card] = float32(subgame.bestResponse(states, opponent) / weight)
+	}
+	return gadget
+}
+
+type weightedState struct {
+	state  games.GameState
+	weight float64
+}
+
+// bestResponse - weighted value of states (all sharing public state) for player best responding to blueprint within subgame
+func (subgame *Subgame) bestResponse(states []weightedState, player acting.ActorID) float64 {
+	first := states[0].state
+	if subgame.isLeaf(first) {
+		value := 0.
+		for _, s := range states {
+			value += s.weight * float64(player) * float64(subgame.evaluate(s.state))
+		}
+		return value
+	}
+	groups := map[interface{}][]weightedState{}
+	order := []interface{}{}
+	for _, s := range states {
+		actions := s.state.Actions()
+		var probabilities []float64
+		if s.state.CurrentActor().GetID() == acting.ChanceId {
+			for _, probability := range games.ChanceProbabilities(s.state, actions) {
+				probabilities = append(probabilities, float64(probability))
+			}
+		} else if s.state.CurrentActor().GetID() != player {
+			probabilities = cfr.StrategyProbabilities(subgame.blueprint, s.state.InformationSet(), actions)
+		}
+		for i, action := range actions {
+			child := s.state.Act(action)
+			weight := s.weight
+			if probabilities != nil {
+				weight *= probabilities[i]
+			}
+			// children are grouped by public information: player actions or public card dealt
+			var key interface{} = action.Name()
+			if poker, ok := child.(games.PokerGameState); ok && s.state.CurrentActor().GetID() == acting.ChanceId {
+				key = poker.Table().Cards[len(poker.Table().Cards)-1]
+			}
+			if _, ok := groups[key]; !ok {
+				order = append(order, key)
+			}
+			groups[key] = append(groups[key], weightedState{child, weight})
+		}
+	}
+	if first.CurrentActor().GetID() == player {
+		best := math.Inf(-1)
+		for _, key := range order {
+			best = math.Max(best, subgame.bestResponse(groups[key], player))
+		}
+		return best
+	}
+	value := 0.
+	for _, key := range order {
+		value += subgame.bestResponse(groups[key], player)
+	}
+	return value
+}
+
+// isLeaf - terminal states and states after the last betting round of subgame
+func (subgame *Subgame) isLeaf(state games.GameState) bool {
+	if state.IsTerminal() {
+		return true
+	}
+	poker, ok := state.(games.PokerGameState)
+	return ok && state.CurrentActor().GetID() == acting.ChanceId && int(poker.Round()-subgame.startRound)+1 >= subgame.rounds
+}
+
+// evaluate - payoff of player A in terminal state, expected payoff of blueprint played by both players in leaves
+func (subgame *Subgame) evaluate(state games.GameState) float32 {
+	if state.IsTerminal() {
+		return state.Evaluate()
+	}
+	key := fmt.Sprint(state)
+	subgame.mutex.Lock()
+	value, ok := subgame.leafValues[key]
+	subgame.mutex.Unlock()
+	if !ok {
+		value = float32(blueprintValue(state, subgame.blueprint))
+		subgame.mutex.Lock()
+		subgame.leafValues[key] = value
+		subgame.mutex.Unlock()
+	}
+	return value
+}
+
+// blueprintValue - expected payoff of player A when both players follow strategy
+func blueprintValue(state games.GameState, strategy cfr.StrategyMap) float64 {
+	if state.IsTerminal() {
+		return float64(state.Evaluate())
+	}
+	actions := state.Actions()
+	var probabilities []float64
+	if state.CurrentActor().GetID() == acting.ChanceId {
+		for _, probability := range games.ChanceProbabilities(state, actions) {
+			probabilities = append(probabilities, float64(probability))
+		}
+	} else {
+		probabilities = cfr.StrategyProbabilities(strategy, state.InformationSet(), actions)
+	}
+	value := 0.
+	for i, action := range actions {
+		if probabilities[i] > 0 {
+			value += probabilities[i] * blueprintValue(state.Act(action), strategy)
+		}
+	}
+	return value
+}
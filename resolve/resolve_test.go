@@ -0,0 +1,123 @@
+package resolve
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/kuhn"
+	"github.com/int8/go-counterfactual-regret-minimization/games/rhodeisland"
+)
+
+func TestResolvingIsSafe(t *testing.T) {
+	root := kuhn.NewRoot(1000.)
+	blueprint := cfr.CreateComputingRoutine(root).ComputeNashEquilibriumViaCFR(300, 1)
+	history := playForTest(t, root, root.Actions()[0], acting.Check)
+
+	subgame, err := NewSubgame(root, blueprint, history, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subgame.Player != acting.PlayerB {
+		t.Errorf("Player B should re-solve after check of player A, got %v", subgame.Player)
+	}
+	for player, ranges := range subgame.Ranges {
+		if sum := sumForTest(ranges); math.Abs(sum-1) > 1e-6 || len(ranges) != 3 {
+			t.Errorf("Range of player %v should be a distribution over 3 cards, got %v", player, ranges)
+		}
+	}
+
+	resolved := subgame.Solve(300 * time.Millisecond)
+	combined := cfr.NewStrategyMap()
+	for infSet, actions := range blueprint.Value {
+		combined.Value[infSet] = actions
+	}
+	for infSet, actions := range resolved.Value {
+		if _, ok := infSet.(gadgetInformationSet); ok {
+			t.Fatal("Gadget information sets should not be returned")
+		}
+		combined.Value[infSet] = actions
+	}
+	if len(resolved.Value) == 0 {
+		t.Fatal("Subgame strategy should be computed")
+	}
+	if before, after := cfr.BestResponseValue(root, blueprint, acting.PlayerA), cfr.BestResponseValue(root, combined, acting.PlayerA); after > before+0.01 {
+		t.Errorf("Re-solved strategy should not be more exploitable than blueprint, best response value %v before and %v after", before, after)
+	}
+	if probabilities := subgame.Policy(resolved).ActionProbabilities(root.Act(history[0])); len(probabilities) == 0 {
+		t.Error("Blueprint should be played outside of subgame")
+	}
+}
+
+func TestDepthLimitedRhodeIslandSubgame(t *testing.T) {
+	defer func(maxRaises int) { rhodeisland.MaxRaises = maxRaises }(rhodeisland.MaxRaises)
+	rhodeisland.MaxRaises = 1
+	root := rhodeisland.NewRoot(1000., cards.CreateLimitedDeck(cards.King, true))
+	blueprint := cfr.CreateComputingRoutine(root).ComputeNashEquilibriumViaCFR(200, 1)
+
+	history := playForTest(t, root, root.Actions()[0], acting.Check, acting.Check, acting.DealPublicCards, acting.Bet)
+	subgame, err := NewSubgame(root, blueprint, history, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	resolved := subgame.Solve(100 * time.Millisecond)
+	if time.Since(start) < 100*time.Millisecond {
+		t.Error("Whole time budget should be used")
+	}
+	state := games.GameState(root)
+	for _, action := range history {
+		state = state.Act(action)
+	}
+	if _, ok := resolved.Value[state.InformationSet()]; !ok {
+		t.Error("Strategy of the public state should be re-solved")
+	}
+	for infSet := range resolved.Value {
+		if len(rhodeisland.SuitIsomorphism{}.Expand(infSet)) == 0 {
+			t.Fatal("Only Rhode Island information sets should be re-solved")
+		}
+	}
+	if len(subgame.leafValues) == 0 {
+		t.Error("Turn should be valued by blueprint")
+	}
+
+	if _, err := NewSubgame(root, blueprint, history, 0); err == nil {
+		t.Error("Subgame without betting rounds should be rejected")
+	}
+	if _, err := NewSubgame(root, blueprint, history[:3], 1); err == nil {
+		t.Error("Subgame can not start at chance node")
+	}
+}
+
+// playForTest - history of actions with given names (first chance action for public cards) after private cards
+func playForTest(t *testing.T, root games.GameState, deal acting.Action, names ...acting.ActionName) []acting.Action {
+	history := []acting.Action{deal}
+	state := root.Act(deal)
+	for _, name := range names {
+		found := false
+		for _, action := range state.Actions() {
+			if action.Name() == name {
+				history = append(history, action)
+				state = state.Act(action)
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("Action %v is not available", name)
+		}
+	}
+	return history
+}
+
+func sumForTest(ranges map[cards.Card]float64) float64 {
+	sum := 0.
+	for _, probability := range ranges {
+		sum += probability
+	}
+	return sum
+}
@@ -0,0 +1,250 @@
+package sequenceform
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+)
+
+// Solution - exact Nash equilibrium of two-player zero-sum game
+type Solution struct {
+	Strategy cfr.StrategyMap
+	// Value - expected payoff of acting.PlayerA when both players follow Strategy
+	Value *big.Rat
+}
+
+type infoSet struct {
+	parent    int
+	actions   []acting.ActionName
+	sequences []int
+}
+
+// sequences - sequences of a single player, sequence 0 is the empty one
+type sequences struct {
+	count    int
+	infoSets map[games.InformationSet]*infoSet
+	order    []games.InformationSet
+}
+
+type sequencePair struct {
+	a int
+	b int
+}
+
+type sequenceForm struct {
+	players map[acting.ActorID]*sequences
+	payoffs map[sequencePair]*big.Rat
+}
+
+// Solve - builds sequence form of the game rooted at root and solves it with linear programming
+func Solve(root games.GameState) (*Solution, error) {
+	form := &sequenceForm{payoffs: map[sequencePair]*big.Rat{}, players: map[acting.ActorID]*sequences{
+		acting.PlayerA: {count: 1, infoSets: map[games.InformationSet]*infoSet{}},
+		acting.PlayerB: {count: 1, infoSets: map[games.InformationSet]*infoSet{}},
+	}}
+	if err := form.walk(root, 0, 0, big.NewRat(1, 1)); err != nil {
+		return nil, err
+	}
+
+	planA, value, err := form.solveForPlayerA()
+	if err != nil {
+		return nil, err
+	}
+	planB, valueB, err := form.solveForPlayerB()
+	if err != nil {
+		return nil, err
+	}
+	if value.Cmp(valueB) != 0 {
+		return nil, fmt.Errorf("sequenceform: values of players differ, %v != %v", value, valueB)
+	}
+
+	strategy := cfr.NewStrategyMap()
+	form.players[acting.PlayerA].behaviouralStrategy(planA, strategy)
+	form.players[acting.PlayerB].behaviouralStrategy(planB, strategy)
+	return &Solution{Strategy: strategy, Value: value}, nil
+}
+
+func (form *sequenceForm) walk(state games.GameState, sequenceA int, sequenceB int, chance *big.Rat) error {
+	if state.IsTerminal() {
+		pair := sequencePair{sequenceA, sequenceB}
+		if _, ok := form.payoffs[pair]; !ok {
+			form.payoffs[pair] = new(big.Rat)
+		}
+		payoff := new(big.Rat).SetFloat64(float64(state.Evaluate()))
+		form.payoffs[pair].Add(form.payoffs[pair], payoff.Mul(payoff, chance))
+		return nil
+	}
+
+	actions := state.Actions()
+	if state.CurrentActor().GetID() == acting.ChanceId {
+		probabilities := games.ExactChanceProbabilities(state, actions)
+		for i, action := range actions {
+			probability := probabilities[i]
+			if err := form.walk(state.Act(action), sequenceA, sequenceB, probability.Mul(probability, chance)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	player := state.CurrentActor().GetID()
+	parent := sequenceA
+	if player == acting.PlayerB {
+		parent = sequenceB
+	}
+	set, err := form.players[player].infoSet(state.InformationSet(), parent, actions)
+	if err != nil {
+		return err
+	}
+	for i, action := range actions {
+		childA, childB := sequenceA, sequenceB
+		if player == acting.PlayerA {
+			childA = set.sequences[i]
+		} else {
+			childB = set.sequences[i]
+		}
+		if err := form.walk(state.Act(action), childA, childB, chance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// infoSet - information set of the player, created with fresh sequences when seen first time
+func (s *sequences) infoSet(key games.InformationSet, parent int, actions []acting.Action) (*infoSet, error) {
+	set, ok := s.infoSets[key]
+	if !ok {
+		set = &infoSet{parent: parent}
+		for _, action := range actions {
+			set.actions = append(set.actions, action.Name())
+			set.sequences = append(set.sequences, s.count)
+			s.count++
+		}
+		s.infoSets[key] = set
+		s.order = append(s.order, key)
+		return set, nil
+	}
+	if set.parent != parent {
+		return nil, errors.New("sequenceform: game has imperfect recall")
+	}
+	if len(set.actions) != len(actions) {
+		return nil, fmt.Errorf("sequenceform: information set %v has inconsistent actions", key)
+	}
+	for i, action := range actions {
+		if set.actions[i] != action.Name() {
+			return nil, fmt.Errorf("sequenceform: information set %v has inconsistent actions", key)
+		}
+	}
+	return set, nil
+}
+
+// constraints - rows of realization plan constraints matrix (E for player A, F for player B)
+func (s *sequences) constraints() [][]*big.Rat {
+	rows := [][]*big.Rat{zeroVector(s.count)}
+	rows[0][0].SetInt64(1)
+	for _, key := range s.order {
+		set := s.infoSets[key]
+		row := zeroVector(s.count)
+		row[set.parent].SetInt64(-1)
+		for _, sequence := range set.sequences {
+			row[sequence].SetInt64(1)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// solveForPlayerA - max q_0 subject to F^T q <= A^T x, E x = e, x >= 0
+func (form *sequenceForm) solveForPlayerA() ([]*big.Rat, *big.Rat, error) {
+	a, b := form.players[acting.PlayerA], form.players[acting.PlayerB]
+	e, f := a.constraints(), b.constraints()
+	lp := newLinearProgram(a.count + 2*len(f))
+	lp.objective[a.count].SetInt64(1)
+	lp.objective[a.count+len(f)].SetInt64(-1)
+
+	for j := 0; j < b.count; j++ {
+		row := zeroVector(a.count + 2*len(f))
+		for r := range f {
+			row[a.count+r].Set(f[r][j])
+			row[a.count+len(f)+r].Neg(f[r][j])
+		}
+		for pair, payoff := range form.payoffs {
+			if pair.b == j {
+				row[pair.a].Sub(row[pair.a], payoff)
+			}
+		}
+		lp.addConstraint(row, lessOrEqual, new(big.Rat))
+	}
+	for r := range e {
+		row := zeroVector(a.count + 2*len(f))
+		copy(row, e[r])
+		lp.addConstraint(row, equal, unitVectorEntry(r))
+	}
+
+	solution, value, err := lp.maximize()
+	if err != nil {
+		return nil, nil, err
+	}
+	return solution[:a.count], value, nil
+}
+
+// solveForPlayerB - min p_0 subject to E^T p >= A y, F y = f, y >= 0
+func (form *sequenceForm) solveForPlayerB() ([]*big.Rat, *big.Rat, error) {
+	a, b := form.players[acting.PlayerA], form.players[acting.PlayerB]
+	e, f := a.constraints(), b.constraints()
+	lp := newLinearProgram(b.count + 2*len(e))
+	lp.objective[b.count].SetInt64(-1)
+	lp.objective[b.count+len(e)].SetInt64(1)
+
+	for i := 0; i < a.count; i++ {
+		row := zeroVector(b.count + 2*len(e))
+		for r := range e {
+			row[b.count+r].Set(e[r][i])
+			row[b.count+len(e)+r].Neg(e[r][i])
+		}
+		for pair, payoff := range form.payoffs {
+			if pair.a == i {
+				row[pair.b].Sub(row[pair.b], payoff)
+			}
+		}
+		lp.addConstraint(row, greaterOrEqual, new(big.Rat))
+	}
+	for r := range f {
+		row := zeroVector(b.count + 2*len(e))
+		copy(row, f[r])
+		lp.addConstraint(row, equal, unitVectorEntry(r))
+	}
+
+	solution, value, err := lp.maximize()
+	if err != nil {
+		return nil, nil, err
+	}
+	return solution[:b.count], value.Neg(value), nil
+}
+
+// behaviouralStrategy - converts realization plan to action probabilities of every information set
+func (s *sequences) behaviouralStrategy(plan []*big.Rat, strategy cfr.StrategyMap) {
+	for _, key := range s.order {
+		set := s.infoSets[key]
+		strategy.Value[key] = map[acting.ActionName]float32{}
+		for i, sequence := range set.sequences {
+			probability := float32(1. / float64(len(set.sequences)))
+			if plan[set.parent].Sign() > 0 {
+				probability, _ = new(big.Rat).Quo(plan[sequence], plan[set.parent]).Float32()
+			}
+			strategy.Value[key][set.actions[i]] = probability
+		}
+	}
+}
+
+// unitVectorEntry - entry of right hand side e = f = (1, 0, ..., 0)
+func unitVectorEntry(r int) *big.Rat {
+	if r == 0 {
+		return big.NewRat(1, 1)
+	}
+	return new(big.Rat)
+}
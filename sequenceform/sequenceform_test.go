@@ -0,0 +1,130 @@
+package sequenceform
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/games/efg"
+	"github.com/int8/go-counterfactual-regret-minimization/games/kuhn"
+)
+
+func TestKuhnPokerGameValue(t *testing.T) {
+	root := kuhn.NewRoot(100.)
+	solution, err := Solve(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if solution.Value.Cmp(big.NewRat(-1, 18)) != 0 {
+		t.Errorf("Kuhn poker is worth exactly -1/18 for player A, got %v", solution.Value)
+	}
+
+	if len(solution.Strategy.Value) != 12 {
+		t.Errorf("Kuhn poker has 12 information sets, strategy has %v", len(solution.Strategy.Value))
+	}
+
+	for infSet, actions := range solution.Strategy.Value {
+		sum := float32(0.)
+		for _, probability := range actions {
+			sum += probability
+		}
+		if math.Abs(float64(sum-1.)) > 1e-6 {
+			t.Errorf("Action probabilities of %v should sum to 1, got %v", infSet, sum)
+		}
+	}
+}
+
+func TestKuhnPokerEquilibriumProperties(t *testing.T) {
+	root := kuhn.NewRoot(100.)
+	solution, err := Solve(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	strategy := solution.Strategy.Value
+
+	jackFirst := root.Act(kuhn.DealPrivateCardsAction{CardA: &cards.JackHearts, CardB: &cards.KingHearts}).InformationSet()
+	kingFirst := root.Act(kuhn.DealPrivateCardsAction{CardA: &cards.KingHearts, CardB: &cards.JackHearts}).InformationSet()
+	alpha := strategy[jackFirst][acting.Bet]
+	if alpha < 0 || alpha > 1./3+1e-6 {
+		t.Errorf("Player A should bluff with jack with probability in [0, 1/3], got %v", alpha)
+	}
+	if math.Abs(float64(strategy[kingFirst][acting.Bet]-3*alpha)) > 1e-6 {
+		t.Errorf("Player A should bet king three times as often as jack, got %v and %v", strategy[kingFirst][acting.Bet], alpha)
+	}
+
+	kingFacingBet := root.Act(kuhn.DealPrivateCardsAction{CardA: &cards.QueenHearts, CardB: &cards.KingHearts}).Act(kuhn.BetAction).InformationSet()
+	if strategy[kingFacingBet][acting.Call] != 1 {
+		t.Errorf("Player B should always call with king, got %v", strategy[kingFacingBet][acting.Call])
+	}
+}
+
+func TestImportedGameValue(t *testing.T) {
+	root, err := efg.Import(strings.NewReader(`EFG 2 R "Myerson's card game" { "Player 1" "Player 2" } ""
+c "" 1 "" { "Red" 1/2 "Black" 1/2 } 0
+p "" 1 1 "" { "Raise" "Fold" } 0
+p "" 2 1 "" { "Meet" "Pass" } 0
+t "" 1 "" { 2, -2 }
+t "" 2 "" { 1, -1 }
+t "" 3 "" { 1, -1 }
+p "" 1 2 "" { "Raise" "Fold" } 0
+p "" 2 1 0
+t "" 4 "" { -2, 2 }
+t "" 2
+t "" 5 "" { -1, 1 }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	solution, err := Solve(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if solution.Value.Cmp(big.NewRat(1, 3)) != 0 {
+		t.Errorf("Myerson's card game is worth exactly 1/3 for player 1, got %v", solution.Value)
+	}
+	red := root.Act(root.Actions()[0])
+	playerB := red.Act(red.Actions()[0])
+	meet := solution.Strategy.Value[playerB.InformationSet()][playerB.Actions()[0].Name()]
+	if math.Abs(float64(meet-2./3)) > 1e-6 {
+		t.Errorf("Player 2 should meet with probability 2/3, got %v", meet)
+	}
+}
+
+func TestImportedChanceProbabilitiesStayExact(t *testing.T) {
+	root, err := efg.Import(strings.NewReader(`EFG 2 R "Lottery" { "Player 1" "Player 2" } ""
+c "" 1 "" { "Win" 1/3 "Draw" 1/7 "Lose" 11/21 } 0
+t "" 1 "" { 3, -3 }
+t "" 2 "" { 0, 0 }
+t "" 3 "" { -1, 1 }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	solution, err := Solve(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if solution.Value.Cmp(big.NewRat(10, 21)) != 0 {
+		t.Errorf("Lottery is worth exactly 10/21 for player 1, got %v", solution.Value)
+	}
+}
+
+func TestSimplexDetectsInfeasibleProgram(t *testing.T) {
+	lp := newLinearProgram(1)
+	lp.addConstraint([]*big.Rat{big.NewRat(1, 1)}, lessOrEqual, big.NewRat(1, 1))
+	lp.addConstraint([]*big.Rat{big.NewRat(1, 1)}, greaterOrEqual, big.NewRat(2, 1))
+	if _, _, err := lp.maximize(); err != errInfeasible {
+		t.Errorf("x <= 1 and x >= 2 should be infeasible, got %v", err)
+	}
+}
+
+func TestSimplexDetectsUnboundedProgram(t *testing.T) {
+	lp := newLinearProgram(2)
+	lp.objective[0].SetInt64(1)
+	lp.addConstraint([]*big.Rat{big.NewRat(1, 1), big.NewRat(-1, 1)}, lessOrEqual, big.NewRat(1, 1))
+	if _, _, err := lp.maximize(); err != errUnbounded {
+		t.Errorf("max x subject to x - y <= 1 should be unbounded, got %v", err)
+	}
+}
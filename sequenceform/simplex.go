@@ -0,0 +1,248 @@
+package sequenceform
+
+import (
+	"errors"
+	"math/big"
+)
+
+type constraintSense int8
+
+const (
+	lessOrEqual constraintSense = iota
+	equal
+	greaterOrEqual
+)
+
+// linearProgram - maximize objective * x subject to rows * x (sense) rhs, x >= 0
+type linearProgram struct {
+	objective []*big.Rat
+	rows      [][]*big.Rat
+	senses    []constraintSense
+	rhs       []*big.Rat
+}
+
+var (
+	errInfeasible = errors.New("sequenceform: linear program is infeasible")
+	errUnbounded  = errors.New("sequenceform: linear program is unbounded")
+)
+
+func newLinearProgram(variables int) *linearProgram {
+	return &linearProgram{objective: zeroVector(variables)}
+}
+
+func (lp *linearProgram) addConstraint(row []*big.Rat, sense constraintSense, rhs *big.Rat) {
+	lp.rows = append(lp.rows, row)
+	lp.senses = append(lp.senses, sense)
+	lp.rhs = append(lp.rhs, rhs)
+}
+
+// tableau - simplex tableau, last row is objective row and last column is right hand side
+type tableau struct {
+	cells   [][]*big.Rat
+	basis   []int
+	allowed []bool
+}
+
+// maximize - two-phase simplex in exact arithmetic, Bland's rule prevents cycling
+func (lp *linearProgram) maximize() ([]*big.Rat, *big.Rat, error) {
+	variables := len(lp.objective)
+	slacks, artificials := 0, 0
+	for i := range lp.rows {
+		if lp.normalizedSense(i) != equal {
+			slacks++
+		}
+		if lp.normalizedSense(i) != lessOrEqual {
+			artificials++
+		}
+	}
+	columns := variables + slacks + artificials
+	t := &tableau{basis: make([]int, len(lp.rows)), allowed: make([]bool, columns)}
+	for j := range t.allowed {
+		t.allowed[j] = true
+	}
+
+	slack, artificial := variables, variables+slacks
+	for i, row := range lp.rows {
+		cells := zeroVector(columns + 1)
+		sign := big.NewRat(1, 1)
+		if lp.rhs[i].Sign() < 0 {
+			sign.SetInt64(-1)
+		}
+		for j, value := range row {
+			cells[j].Mul(value, sign)
+		}
+		cells[columns].Mul(lp.rhs[i], sign)
+		switch lp.normalizedSense(i) {
+		case lessOrEqual:
+			cells[slack].SetInt64(1)
+			t.basis[i] = slack
+			slack++
+		case greaterOrEqual:
+			cells[slack].SetInt64(-1)
+			slack++
+			fallthrough
+		case equal:
+			cells[artificial].SetInt64(1)
+			t.basis[i] = artificial
+			artificial++
+		}
+		t.cells = append(t.cells, cells)
+	}
+
+	// phase 1: drive artificial variables to zero
+	phase1 := zeroVector(columns)
+	for j := variables + slacks; j < columns; j++ {
+		phase1[j].SetInt64(-1)
+	}
+	t.cells = append(t.cells, nil)
+	t.setObjective(phase1)
+	if err := t.run(); err != nil {
+		return nil, nil, err
+	}
+	if t.value().Sign() != 0 {
+		return nil, nil, errInfeasible
+	}
+	t.removeArtificials(variables + slacks)
+
+	// phase 2: original objective
+	phase2 := zeroVector(columns)
+	for j, value := range lp.objective {
+		phase2[j].Set(value)
+	}
+	t.setObjective(phase2)
+	if err := t.run(); err != nil {
+		return nil, nil, err
+	}
+
+	solution := zeroVector(variables)
+	for i, column := range t.basis {
+		if column < variables {
+			solution[column].Set(t.cells[i][columns])
+		}
+	}
+	return solution, t.value(), nil
+}
+
+func (lp *linearProgram) normalizedSense(i int) constraintSense {
+	if lp.rhs[i].Sign() >= 0 || lp.senses[i] == equal {
+		return lp.senses[i]
+	}
+	if lp.senses[i] == lessOrEqual {
+		return greaterOrEqual
+	}
+	return lessOrEqual
+}
+
+func (t *tableau) objectiveRow() []*big.Rat {
+	return t.cells[len(t.cells)-1]
+}
+
+func (t *tableau) value() *big.Rat {
+	row := t.objectiveRow()
+	return row[len(row)-1]
+}
+
+// setObjective - objective row of reduced costs for maximizing costs * x in current basis
+func (t *tableau) setObjective(costs []*big.Rat) {
+	row := zeroVector(len(costs) + 1)
+	for j, cost := range costs {
+		row[j].Neg(cost)
+	}
+	t.cells[len(t.cells)-1] = row
+	product := new(big.Rat)
+	for i, column := range t.basis {
+		if row[column].Sign() == 0 {
+			continue
+		}
+		factor := new(big.Rat).Set(row[column])
+		for j, cell := range t.cells[i] {
+			row[j].Sub(row[j], product.Mul(factor, cell))
+		}
+	}
+}
+
+func (t *tableau) run() error {
+	constraints := len(t.basis)
+	for {
+		objective := t.objectiveRow()
+		entering := -1
+		for j := 0; j < len(objective)-1; j++ {
+			if t.allowed[j] && objective[j].Sign() < 0 {
+				entering = j
+				break
+			}
+		}
+		if entering < 0 {
+			return nil
+		}
+		leaving := -1
+		var best, ratio big.Rat
+		for i := 0; i < constraints; i++ {
+			if t.cells[i][entering].Sign() <= 0 {
+				continue
+			}
+			ratio.Quo(t.cells[i][len(objective)-1], t.cells[i][entering])
+			if leaving < 0 || ratio.Cmp(&best) < 0 || (ratio.Cmp(&best) == 0 && t.basis[i] < t.basis[leaving]) {
+				leaving = i
+				best.Set(&ratio)
+			}
+		}
+		if leaving < 0 {
+			return errUnbounded
+		}
+		t.pivot(leaving, entering)
+	}
+}
+
+func (t *tableau) pivot(row int, column int) {
+	pivotRow := t.cells[row]
+	divisor := new(big.Rat).Set(pivotRow[column])
+	for j := range pivotRow {
+		pivotRow[j].Quo(pivotRow[j], divisor)
+	}
+	product := new(big.Rat)
+	for i, cells := range t.cells {
+		if i == row || cells[column].Sign() == 0 {
+			continue
+		}
+		factor := new(big.Rat).Set(cells[column])
+		for j := range cells {
+			cells[j].Sub(cells[j], product.Mul(factor, pivotRow[j]))
+		}
+	}
+	t.basis[row] = column
+}
+
+// removeArtificials - pivots zero-valued artificial variables out of basis, drops redundant rows
+func (t *tableau) removeArtificials(firstArtificial int) {
+	for j := firstArtificial; j < len(t.allowed); j++ {
+		t.allowed[j] = false
+	}
+	for i := 0; i < len(t.basis); i++ {
+		if t.basis[i] < firstArtificial {
+			continue
+		}
+		replacement := -1
+		for j := 0; j < firstArtificial; j++ {
+			if t.cells[i][j].Sign() != 0 {
+				replacement = j
+				break
+			}
+		}
+		if replacement >= 0 {
+			t.pivot(i, replacement)
+			continue
+		}
+		t.cells = append(t.cells[:i], t.cells[i+1:]...)
+		t.basis = append(t.basis[:i], t.basis[i+1:]...)
+		i--
+	}
+}
+
+func zeroVector(size int) []*big.Rat {
+	vector := make([]*big.Rat, size)
+	for i := range vector {
+		vector[i] = new(big.Rat)
+	}
+	return vector
+}
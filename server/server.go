@@ -0,0 +1,228 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/cards"
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/bundled"
+	"github.com/int8/go-counterfactual-regret-minimization/games/notation"
+	"github.com/int8/go-counterfactual-regret-minimization/policy"
+	"github.com/int8/go-counterfactual-regret-minimization/rounds"
+)
+
+// suitLetters - suit symbols (as printed by cards.Card) accepted in JSON cards along with letters of cards.ParseCard
+var suitLetters = map[string]string{"♥": "h", "♦": "d", "♠": "s", "♣": "c"}
+
+// Card - card in JSON, rank is one of 2-10 J Q K A, suit one of ♥ ♦ ♠ ♣ (or h d s c)
+type Card struct {
+	Rank string `json:"rank"`
+	Suit string `json:"suit"`
+}
+
+// Query - state seen by the player to act: its private card, public cards and betting actions so far (check, bet, call, raise or fold),
+// alternatively the whole hand in notation of games/notation package (e.g. "Kh Qh | rc/c Jh")
+type Query struct {
+	Card    Card     `json:"card"`
+	Board   []Card   `json:"board"`
+	History []string `json:"history"`
+	State   string   `json:"state"`
+}
+
+// ActionProbability - legal action along with probability of playing it
+type ActionProbability struct {
+	Action      string  `json:"action"`
+	Probability float64 `json:"probability"`
+}
+
+// Answer - legal actions of the player to act, Known is false when strategy has no entry for the information set (uniform answer)
+type Answer struct {
+	Player  string              `json:"player"`
+	Actions []ActionProbability `json:"actions"`
+	Known   bool                `json:"known"`
+}
+
+type errorAnswer struct {
+	Error string `json:"error"`
+}
+
+// Server - answers strategy queries over HTTP: POST /query and GET /healthz
+type Server struct {
+	root     games.GameState
+	strategy cfr.StrategyMap
+	bot      policy.Policy
+	mux      *http.ServeMux
+}
+
+// New - server of strategy computed for a bundled game, note it sets rhodeisland.MaxRaises
+func New(config bundled.Config, strategy cfr.StrategyMap) (*Server, error) {
+	root, err := config.Root()
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{root: root, strategy: strategy, bot: policy.NewStrategyPolicy(strategy, nil), mux: http.NewServeMux()}
+	s.mux.HandleFunc("/query", s.query)
+	s.mux.HandleFunc("/healthz", s.healthz)
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprintln(w, "ok")
+}
+
+func (s *Server) query(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorAnswer{"query has to be POSTed"})
+		return
+	}
+	query := Query{}
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorAnswer{fmt.Sprintf("malformed query: %v", err)})
+		return
+	}
+	answer, err := s.Answer(query)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorAnswer{err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, answer)
+}
+
+// Answer - strategy in the queried state
+func (s *Server) Answer(query Query) (Answer, error) {
+	state, err := s.State(query)
+	if err != nil {
+		return Answer{}, err
+	}
+	_, known := s.strategy.Value[state.InformationSet()]
+	probabilities := s.bot.ActionProbabilities(state)
+	answer := Answer{Player: acting.PlayerName(state.CurrentActor().GetID()), Known: known}
+	for _, action := range state.Actions() {
+		answer.Actions = append(answer.Actions, ActionProbability{action.Name().Word(), probabilities[action.Name()]})
+	}
+	return answer, nil
+}
+
+// State - game state of the query, opponent's private card is any card not seen by the player
+func (s *Server) State(query Query) (games.PokerGameState, error) {
+	if query.State != "" {
+		return s.replayNotation(query.State)
+	}
+	card, err := parseCard(query.Card)
+	if err != nil {
+		return nil, err
+	}
+	board := make([]cards.Card, len(query.Board))
+	for i := range query.Board {
+		if board[i], err = parseCard(query.Board[i]); err != nil {
+			return nil, err
+		}
+	}
+	// seat of the player follows from the history, replay as player A first
+	state, err := s.replay(acting.PlayerA, card, board, query.History)
+	if err != nil {
+		return nil, err
+	}
+	if state.CurrentActor().GetID() == acting.PlayerB {
+		return s.replay(acting.PlayerB, card, board, query.History)
+	}
+	return state, nil
+}
+
+func (s *Server) replay(seat acting.ActorID, card cards.Card, board []cards.Card, history []string) (games.PokerGameState, error) {
+	state, ok := s.root.(games.PokerGameState)
+	if !ok {
+		return nil, errors.New("server: game is not poker")
+	}
+	dealt := 0
+	for {
+		if state.IsTerminal() {
+			return nil, errors.New("server: hand is over")
+		}
+		if state.CurrentActor().GetID() != acting.ChanceId {
+			if len(history) == 0 {
+				break
+			}
+			word := strings.ToLower(strings.TrimSpace(history[0]))
+			action := games.FindAction(state, func(name acting.ActionName) bool { return name.Word() == word })
+			if action == nil {
+				return nil, fmt.Errorf("server: action %q is not legal", word)
+			}
+			state = state.Act(action).(games.PokerGameState)
+			history = history[1:]
+			continue
+		}
+		if state.Round() == rounds.Start {
+			// card of the seat, any card not seen by the player for its opponent
+			child := games.DealPrivateCards(state, func(cardA cards.Card, cardB cards.Card) bool {
+				if seat == acting.PlayerB {
+					cardA, cardB = cardB, cardA
+				}
+				return cardA == card && !cards.Contains(board, cardB)
+			})
+			if child == nil {
+				return nil, fmt.Errorf("server: card %v can not be dealt", card)
+			}
+			state = child
+			continue
+		}
+		if dealt == len(board) {
+			return nil, fmt.Errorf("server: board card %v is missing", dealt+1)
+		}
+		child := games.DealPublicCard(state, board[dealt])
+		if child == nil {
+			return nil, fmt.Errorf("server: board card %v can not be dealt", board[dealt])
+		}
+		state = child
+		dealt++
+	}
+	if dealt != len(board) {
+		return nil, fmt.Errorf("server: %v board cards given, %v dealt so far", len(board), dealt)
+	}
+	return state, nil
+}
+
+func (s *Server) replayNotation(text string) (games.PokerGameState, error) {
+	root, ok := s.root.(games.PokerGameState)
+	if !ok {
+		return nil, errors.New("server: game is not poker")
+	}
+	state, err := notation.Replay(root, text)
+	if err != nil {
+		return nil, err
+	}
+	if state.IsTerminal() || state.CurrentActor().GetID() == acting.ChanceId {
+		return nil, fmt.Errorf("server: no player is to act in %q", text)
+	}
+	return state, nil
+}
+
+// parseCard - JSON card in notation of cards.ParseCard, rank 10 and suit symbols are accepted too
+func parseCard(card Card) (cards.Card, error) {
+	rank, suit := strings.ToUpper(card.Rank), strings.ToLower(card.Suit)
+	if rank == "10" {
+		rank = "T"
+	}
+	if letter, ok := suitLetters[suit]; ok {
+		suit = letter
+	}
+	return cards.ParseCard(rank + suit)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/int8/go-counterfactual-regret-minimization/cfr"
+	"github.com/int8/go-counterfactual-regret-minimization/games/bundled"
+)
+
+func TestHealthz(t *testing.T) {
+	ts := createTestServer(t, bundled.Config{Game: bundled.Kuhn}, cfr.NewStrategyMap())
+	defer ts.Close()
+
+	response, err := http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("Health endpoint should answer 200, got %v", response.StatusCode)
+	}
+}
+
+func TestKuhnQueryFollowsStrategy(t *testing.T) {
+	config := bundled.Config{Game: bundled.Kuhn}
+	root, _ := config.Root()
+	strategy := cfr.CreateComputingRoutine(root).ComputeNashEquilibriumViaCFR(20000, 1)
+	ts := createTestServer(t, config, strategy)
+	defer ts.Close()
+
+	status, answer := postQuery(t, ts, `{"card": {"rank": "J", "suit": "♥"}, "history": ["bet"]}`)
+	if status != http.StatusOK {
+		t.Fatalf("Query should succeed, got %v", status)
+	}
+	if answer.Player != "B" || !answer.Known || len(answer.Actions) != 2 {
+		t.Fatalf("Player B should call or fold according to strategy, got %+v", answer)
+	}
+	for _, action := range answer.Actions {
+		if action.Action == "fold" && action.Probability < 0.95 {
+			t.Errorf("Jack should fold against a bet, got %+v", answer.Actions)
+		}
+	}
+
+	status, answer = postQuery(t, ts, `{"card": {"rank": "K", "suit": "h"}, "history": []}`)
+	if status != http.StatusOK || answer.Player != "A" || answer.Actions[0].Action != "check" || answer.Actions[1].Action != "bet" {
+		t.Errorf("Player A should check or bet, got %v %+v", status, answer)
+	}
+}
+
+func TestRhodeIslandQueryReplaysBoard(t *testing.T) {
+	ts := createTestServer(t, bundled.Config{Game: bundled.RhodeIsland, MinCard: 10, MaxRaises: 1}, cfr.NewStrategyMap())
+	defer ts.Close()
+
+	status, answer := postQuery(t, ts, `{"card": {"rank": "A", "suit": "♠"}, "board": [{"rank": "10", "suit": "♣"}], "history": ["check", "check", "bet"]}`)
+	if status != http.StatusOK {
+		t.Fatalf("Query should succeed, got %v", status)
+	}
+	if answer.Player != "B" || answer.Known || len(answer.Actions) != 3 {
+		t.Fatalf("Player B should face a bet on flop, got %+v", answer)
+	}
+	for _, action := range answer.Actions {
+		if action.Probability < 0.33 || action.Probability > 0.34 {
+			t.Errorf("Unknown information set should be played uniformly, got %+v", answer.Actions)
+		}
+	}
+}
+
+func TestQueryInNotation(t *testing.T) {
+	ts := createTestServer(t, bundled.Config{Game: bundled.RhodeIsland, MinCard: 10, MaxRaises: 1}, cfr.NewStrategyMap())
+	defer ts.Close()
+
+	status, answer := postQuery(t, ts, `{"state": "Kh As | cc/r Tc"}`)
+	if status != http.StatusOK || answer.Player != "B" || len(answer.Actions) != 3 {
+		t.Errorf("Player B should face a bet on flop, got %v %+v", status, answer)
+	}
+	for _, query := range []string{`{"state": "Kh As | cc/"}`, `{"state": "Kh As | rf"}`, `{"state": "Kh As | x"}`} {
+		if status, _ := postQuery(t, ts, query); status != http.StatusBadRequest {
+			t.Errorf("Query %v should be rejected, got %v", query, status)
+		}
+	}
+}
+
+func TestInvalidQueries(t *testing.T) {
+	ts := createTestServer(t, bundled.Config{Game: bundled.RhodeIsland, MinCard: 10, MaxRaises: 1}, cfr.NewStrategyMap())
+	defer ts.Close()
+
+	queries := []string{
+		`{"card": {"rank": "X", "suit": "♠"}}`,
+		`{"card": {"rank": "2", "suit": "♠"}}`,
+		`{"card": {"rank": "A", "suit": "♠"}, "history": ["call"]}`,
+		`{"card": {"rank": "A", "suit": "♠"}, "history": ["bet", "fold"]}`,
+		`{"card": {"rank": "A", "suit": "♠"}, "history": ["check", "check"]}`,
+		`{"card": {"rank": "A", "suit": "♠"}, "board": [{"rank": "A", "suit": "♠"}], "history": ["check", "check"]}`,
+		`{"card": {"rank": "A", "suit": "♠"}, "board": [{"rank": "K", "suit": "♠"}]}`,
+		`not json`,
+	}
+	for _, query := range queries {
+		if status, _ := postQuery(t, ts, query); status != http.StatusBadRequest {
+			t.Errorf("Query %v should be rejected, got %v", query, status)
+		}
+	}
+
+	response, err := http.Get(ts.URL + "/query")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET query should not be allowed, got %v", response.StatusCode)
+	}
+}
+
+func createTestServer(t *testing.T, config bundled.Config, strategy cfr.StrategyMap) *httptest.Server {
+	s, err := New(config, strategy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(s)
+}
+
+func postQuery(t *testing.T, ts *httptest.Server, query string) (int, Answer) {
+	response, err := http.Post(ts.URL+"/query", "application/json", strings.NewReader(query))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body := &bytes.Buffer{}
+	body.ReadFrom(response.Body)
+	answer := Answer{}
+	if response.StatusCode == http.StatusOK {
+		if err := json.Unmarshal(body.Bytes(), &answer); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return response.StatusCode, answer
+}
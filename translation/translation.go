@@ -0,0 +1,139 @@
+// Package translation - maps real bet sizes of opponents (e.g. off-tree bets of no-limit players) to betting actions of the game tree
+//
+// Bets are measured as fractions of the pot before the bet. Bet x lying between two bets a < b of the tree is
+// translated to a with pseudo-harmonic probability (Ganzfried and Sandholm, 2013)
+//
+//	f(x) = (b - x)(1 + a) / ((b - a)(1 + x))
+//
+// and to b otherwise. Bets smaller than the smallest bet of the tree (larger than the largest one) are translated to it.
+package translation
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/policy"
+)
+
+// Rounding - how one of two neighbouring bets of the tree is picked
+type Rounding int8
+
+const (
+	// Randomized - smaller bet is picked with pseudo-harmonic probability
+	Randomized Rounding = iota
+	// Deterministic - more probable of the two bets is picked (smaller one on a tie)
+	Deterministic
+)
+
+// PseudoHarmonic - probability of translating bet x to the smaller of bets a < b, all bets are fractions of the pot
+func PseudoHarmonic(a float64, b float64, x float64) float64 {
+	if x <= a {
+		return 1
+	}
+	if x >= b {
+		return 0
+	}
+	return (b - x) * (1 + a) / ((b - a) * (1 + x))
+}
+
+// Translator - translates bets to actions of game states implementing games.BetSizer
+type Translator struct {
+	Rounding Rounding
+}
+
+type sizedAction struct {
+	action acting.Action
+	size   float64
+}
+
+// Probabilities - bets and raises of state bet of amount chips is translated to, with their probabilities
+func (translator Translator) Probabilities(state games.PokerGameState, amount float32) (map[acting.ActionName]float64, error) {
+	bets, err := sizedBets(state)
+	if err != nil {
+		return nil, err
+	}
+	x := size(state, amount)
+	if x <= bets[0].size {
+		return map[acting.ActionName]float64{bets[0].action.Name(): 1}, nil
+	}
+	for i := 1; i < len(bets); i++ {
+		if x < bets[i].size {
+			lower := PseudoHarmonic(bets[i-1].size, bets[i].size, x)
+			return map[acting.ActionName]float64{bets[i-1].action.Name(): lower, bets[i].action.Name(): 1 - lower}, nil
+		}
+	}
+	return map[acting.ActionName]float64{bets[len(bets)-1].action.Name(): 1}, nil
+}
+
+// Translate - action of state standing for bet of amount chips, draw (uniform in [0, 1)) picks the smaller
+// of neighbouring bets when it is below pseudo-harmonic probability and rounding is randomized
+func (translator Translator) Translate(state games.PokerGameState, amount float32, draw float64) (acting.Action, error) {
+	bets, err := sizedBets(state)
+	if err != nil {
+		return nil, err
+	}
+	x := size(state, amount)
+	for i := 1; i < len(bets); i++ {
+		if x < bets[i].size {
+			lower := PseudoHarmonic(bets[i-1].size, bets[i].size, x)
+			if (translator.Rounding == Deterministic && lower >= 0.5) || (translator.Rounding == Randomized && draw < lower) {
+				return bets[i-1].action, nil
+			}
+			return bets[i].action, nil
+		}
+	}
+	if x <= bets[0].size {
+		return bets[0].action, nil
+	}
+	return bets[len(bets)-1].action, nil
+}
+
+// Respond - action picked by policy once opponent acting in state bets amount chips, rng defaults to math/rand global source when nil
+func (translator Translator) Respond(p policy.Policy, state games.PokerGameState, amount float32, rng *rand.Rand) (acting.Action, error) {
+	var draw float64
+	if rng != nil {
+		draw = rng.Float64()
+	} else {
+		draw = rand.Float64()
+	}
+	action, err := translator.Translate(state, amount, draw)
+	if err != nil {
+		return nil, err
+	}
+	next := state.Act(action)
+	if next.IsTerminal() || next.CurrentActor().GetID() == acting.ChanceId {
+		return nil, fmt.Errorf("translation: there is nothing to respond to after %v", action.Name())
+	}
+	return p.Sample(next, rng), nil
+}
+
+// sizedBets - bets and raises of state sorted by their size
+func sizedBets(state games.PokerGameState) ([]sizedAction, error) {
+	sizer, ok := state.(games.BetSizer)
+	if !ok {
+		return nil, errors.New("translation: game does not tell bet sizes")
+	}
+	bets := []sizedAction{}
+	for _, action := range state.Actions() {
+		if action.Name() == acting.Bet || action.Name() == acting.Raise {
+			bets = append(bets, sizedAction{action, size(state, sizer.BetAmount(action))})
+		}
+	}
+	if len(bets) == 0 {
+		return nil, errors.New("translation: there are no bets to translate to")
+	}
+	sort.Slice(bets, func(i, j int) bool { return bets[i].size < bets[j].size })
+	return bets, nil
+}
+
+func size(state games.PokerGameState, amount float32) float64 {
+	pot := float64(state.Table().Pot)
+	if pot <= 0 {
+		return float64(amount)
+	}
+	return float64(amount) / pot
+}
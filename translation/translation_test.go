@@ -0,0 +1,126 @@
+package translation
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/int8/go-counterfactual-regret-minimization/acting"
+	"github.com/int8/go-counterfactual-regret-minimization/games"
+	"github.com/int8/go-counterfactual-regret-minimization/games/bundled"
+	"github.com/int8/go-counterfactual-regret-minimization/games/rhodeisland"
+	"github.com/int8/go-counterfactual-regret-minimization/policy"
+)
+
+func TestPseudoHarmonicBoundaries(t *testing.T) {
+	if PseudoHarmonic(0.5, 1, 0.5) != 1 || PseudoHarmonic(0.5, 1, 1) != 0 {
+		t.Error("Bets of the tree should be translated to themselves")
+	}
+	if PseudoHarmonic(0.5, 1, 0.1) != 1 || PseudoHarmonic(0.5, 1, 3) != 0 {
+		t.Error("Bets outside of the interval should be translated to its closest end")
+	}
+	if math.Abs(PseudoHarmonic(0.5, 1, 0.75)-0.375/0.875) > 1e-9 {
+		t.Errorf("Three quarter pot bet should map to half pot bet with probability 3/7, got %v", PseudoHarmonic(0.5, 1, 0.75))
+	}
+	// mapping is not linear, geometric mean of pot sizes is a better midpoint
+	if PseudoHarmonic(0.5, 1, 0.75) >= 0.5 {
+		t.Error("Midpoint of bet sizes should map to larger bet more often")
+	}
+}
+
+func TestPseudoHarmonicIsMonotone(t *testing.T) {
+	for _, interval := range [][2]float64{{0.25, 0.5}, {0.5, 1}, {1, 4}} {
+		previous := 1.
+		for x := interval[0]; x <= interval[1]; x += 0.01 {
+			probability := PseudoHarmonic(interval[0], interval[1], x)
+			if probability > previous || probability < 0 || probability > 1 {
+				t.Fatalf("Probability of smaller bet should decrease with bet size within %v, got %v at %v", interval, probability, x)
+			}
+			previous = probability
+		}
+	}
+}
+
+// twoBets - state offering half pot bet and pot sized raise
+type twoBets struct {
+	games.PokerGameState
+}
+
+func (state twoBets) Actions() []acting.Action {
+	return []acting.Action{rhodeisland.CheckAction, rhodeisland.BetAction, rhodeisland.RaiseAction}
+}
+
+func (state twoBets) BetAmount(action acting.Action) float32 {
+	switch action.Name() {
+	case acting.Bet:
+		return state.Table().Pot / 2
+	case acting.Raise:
+		return state.Table().Pot
+	}
+	return 0
+}
+
+func TestTranslatorPicksNeighbouringBets(t *testing.T) {
+	state := twoBets{createStateForTest(t, "Ks Qh |")}
+	pot := state.Table().Pot
+
+	deterministic := Translator{Rounding: Deterministic}
+	for _, c := range []struct {
+		fraction float32
+		expected acting.ActionName
+	}{{0.1, acting.Bet}, {0.5, acting.Bet}, {0.6, acting.Bet}, {0.8, acting.Raise}, {1, acting.Raise}, {5, acting.Raise}} {
+		action, err := deterministic.Translate(state, c.fraction*pot, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if action.Name() != c.expected {
+			t.Errorf("Bet of %v pot should be translated to %v, got %v", c.fraction, c.expected, action.Name())
+		}
+	}
+
+	randomized := Translator{Rounding: Randomized}
+	probabilities, err := randomized.Probabilities(state, 0.75*pot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(probabilities[acting.Bet]-3./7) > 1e-6 || math.Abs(probabilities[acting.Bet]+probabilities[acting.Raise]-1) > 1e-6 {
+		t.Errorf("Probabilities of neighbouring bets should be pseudo-harmonic, got %v", probabilities)
+	}
+	smaller, _ := randomized.Translate(state, 0.75*pot, 0.4)
+	larger, _ := randomized.Translate(state, 0.75*pot, 0.5)
+	if smaller.Name() != acting.Bet || larger.Name() != acting.Raise {
+		t.Errorf("Draw should pick the bet by pseudo-harmonic probability, got %v and %v", smaller.Name(), larger.Name())
+	}
+}
+
+func TestRespondToOffTreeBet(t *testing.T) {
+	state := createStateForTest(t, "Ks Qh |")
+	translator := Translator{Rounding: Randomized}
+	action, err := translator.Respond(policy.AlwaysCall, state, 37, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action.Name() != acting.Call {
+		t.Errorf("Policy should answer the bet the off-tree bet is translated to, got %v", action.Name())
+	}
+
+	facingBet := createStateForTest(t, "Ks Qh | r")
+	if _, err := translator.Translate(facingBet, 5, 0); err != nil {
+		t.Error("Raise should be available to translate to")
+	}
+	if _, err := translator.Translate(createStateForTest(t, "Ks Qh | rr"), 5, 0); err == nil {
+		t.Error("Translation should fail when there is no bet in the tree")
+	}
+}
+
+func createStateForTest(t *testing.T, hand string) games.PokerGameState {
+	root, err := bundled.Config{Game: bundled.RhodeIsland, MinCard: 10, MaxRaises: 1}.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := rhodeisland.ParseState(root.(*rhodeisland.RIGameState), hand)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state
+}